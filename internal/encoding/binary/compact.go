@@ -0,0 +1,485 @@
+package binary
+
+import (
+	"fmt"
+	"hash/crc32"
+	"math"
+	"time"
+)
+
+var compactFieldReaders = [...]func(*Record, *sliceReader) error{
+	fieldTimestamp: readCompactTimestamp,
+	fieldLevel:     readLevel,
+	fieldPkg:       readCompactPkg,
+	fieldFile:      readCompactFile,
+	fieldLine:      readCompactLine,
+	fieldMark:      readMark,
+	fieldMsg:       readCompactMsg,
+	fieldContext:   readCompactContext,
+	fieldStack:     readCompactStack,
+	fieldFormat:    readCompactFormat,
+	fieldTemplate:  readCompactTemplate,
+	fieldVerbosity: readCompactVerbosity,
+	fieldFunc:      readCompactFunc,
+	fieldZone:      readCompactZone,
+	fieldSeq:       readCompactSeq,
+}
+
+// A version 1 record is framed by its length and a CRC32C checksum. A chained
+// record begins with chainMark in place of the magic and version.
+const (
+	chainMark     = 0x81
+	maxRecordSize = 64 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+func readFramedRecord(record *Record, reader *sliceReader) error {
+	body, err := readFrame(reader)
+	if err != nil {
+		return err
+	}
+	return readFrameBody(record, reader, len(body))
+}
+
+// readChainedRecord reads a record whose chainMark has been consumed.
+func readChainedRecord(record *Record, reader *sliceReader) error {
+	headerStart := reader.pos - 1
+	size, err := readUvarint(reader)
+	if err != nil {
+		return err
+	}
+	if size > maxRecordSize {
+		return newFormatError(fmt.Sprintf("record too long: %d", size))
+	}
+	header := reader.data[headerStart:reader.pos]
+	expected, err := readUint32(reader)
+	if err != nil {
+		return err
+	}
+	body, err := reader.next(int(size))
+	if err != nil {
+		return err
+	}
+	if actual := frameChecksum(header, body); actual != expected {
+		return newChecksumError(expected, actual)
+	}
+	return readFrameBody(record, reader, len(body))
+}
+
+// readFrameBody decodes the body of size bytes that the reader has just
+// passed on its own, so that decoding cannot run past its length.
+func readFrameBody(record *Record, reader *sliceReader, size int) error {
+	data, end := reader.data, reader.pos
+	reader.data, reader.pos = reader.data[:end], end-size
+	err := readCompactRecord(record, reader)
+	if err == errShortData {
+		err = newFormatError("record body ends prematurely")
+	} else if err == nil && reader.pos != end {
+		err = newFormatError("record body has trailing bytes")
+	}
+	reader.data, reader.pos = data, end
+	return err
+}
+
+// readFrame reads the length and checksum following the version of a frame
+// and returns its body once it is verified.
+func readFrame(reader *sliceReader) ([]byte, error) {
+	headerStart := reader.pos - sizeOfVersion
+	size, err := readUint32(reader)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRecordSize {
+		return nil, newFormatError(fmt.Sprintf("record too long: %d", size))
+	}
+	expected, err := readUint32(reader)
+	if err != nil {
+		return nil, err
+	}
+	body, err := reader.next(int(size))
+	if err != nil {
+		return nil, err
+	}
+	if actual := frameChecksum(reader.data[headerStart:headerStart+sizeOfVersion+sizeOfLength], body); actual != expected {
+		return nil, newChecksumError(expected, actual)
+	}
+	return body, nil
+}
+
+// checkFrame tells by its header whether a record can be decoded from the
+// magic at the beginning of data.
+func checkFrame(data []byte, started bool) error {
+	if len(data) < sizeOfHeader {
+		return errShortData
+	}
+	version := data[sizeOfMagic]
+	switch version {
+	case legacyVersion, binaryVersion:
+		return nil
+	case compactVersion, headerMark:
+	default:
+		return newVersionError(data[sizeOfMagic])
+	}
+
+	if len(data) < sizeOfFrameHeader {
+		return errShortData
+	}
+	size := getUint32(data[sizeOfHeader:])
+	if size > maxRecordSize {
+		return newFormatError(fmt.Sprintf("record too long: %d", size))
+	}
+	end := sizeOfFrameHeader + int(size)
+	if len(data) < end {
+		return errShortData
+	}
+	body := data[sizeOfFrameHeader:end]
+	expected := getUint32(data[sizeOfHeader+sizeOfLength:])
+	if actual := frameChecksum(data[sizeOfMagic:sizeOfHeader+sizeOfLength], body); actual != expected {
+		return newChecksumError(expected, actual)
+	}
+	if version == compactVersion && !started && (size == 0 || fieldKind(body[0]) != fieldReset) {
+		return errNotStarted
+	}
+	return nil
+}
+
+// frameChecksum computes the checksum of a frame given its header from the
+// version or chainMark up to the checksum, and its body.
+func frameChecksum(header, body []byte) uint32 {
+	sum := crc32.Update(0, crcTable, header)
+	return crc32.Update(sum, crcTable, body)
+}
+
+// readCompactRecord reads the fields of a version 1 record.
+func readCompactRecord(record *Record, reader *sliceReader) error {
+	state := reader.state
+	if state == nil {
+		// Without a stream, only a restarting record can be decoded.
+		state = new(streamState)
+		reader.state = state
+	}
+
+	mark := state.mark()
+	err := readCompactFields(record, reader)
+	if err != nil {
+		state.rollback(mark)
+		return err
+	}
+	state.commit()
+	return nil
+}
+
+func readCompactFields(record *Record, reader *sliceReader) error {
+	kind, err := readFieldKind(reader)
+	if err != nil {
+		return err
+	}
+	if kind == fieldReset {
+		reader.state.reset()
+	} else if !reader.state.started {
+		return errNotStarted
+	} else {
+		reader.pos--
+	}
+
+	for {
+		kind, err := readFieldKind(reader)
+		if err != nil {
+			return err
+		}
+
+		if kind == fieldEnd {
+			return nil
+		}
+
+		fieldReader := compactFieldReaders[kind]
+		if fieldReader == nil {
+			return newFormatError(fmt.Sprintf("unexpected field kind %d", kind))
+		}
+		if err = fieldReader(record, reader); err != nil {
+			return err
+		}
+	}
+}
+
+func readCompactTimestamp(record *Record, reader *sliceReader) error {
+	delta, err := readVarint(reader)
+	if err != nil {
+		return err
+	}
+	state := reader.state
+	location, _ := fixedZone(state.baseZone)
+	record.Time = time.Unix(0, state.time(delta)).In(location)
+	return nil
+}
+
+func readCompactZone(record *Record, reader *sliceReader) error {
+	offset, err := readVarint(reader)
+	if err != nil {
+		return err
+	}
+	if offset < -maxZoneOffset || offset > maxZoneOffset {
+		return newFormatError(fmt.Sprintf("zone offset out of range: %d", offset))
+	}
+	location, _ := fixedZone(int(offset))
+	reader.state.zone(int(offset))
+	record.Time = record.Time.In(location)
+	return nil
+}
+
+func readCompactSeq(record *Record, reader *sliceReader) error {
+	delta, err := readVarint(reader)
+	if err == nil {
+		record.Seq = reader.state.seq(delta)
+	}
+	return err
+}
+
+func readCompactPkg(record *Record, reader *sliceReader) error {
+	pkg, err := readInterned(reader)
+	if err == nil {
+		record.Pkg = pkg
+	}
+	return err
+}
+
+func readCompactFile(record *Record, reader *sliceReader) error {
+	file, err := readInterned(reader)
+	if err == nil {
+		record.File = file
+	}
+	return err
+}
+
+func readCompactLine(record *Record, reader *sliceReader) error {
+	line, err := readCompactInt(reader, math.MaxUint32)
+	if err == nil {
+		record.Line = line
+	}
+	return err
+}
+
+func readCompactVerbosity(record *Record, reader *sliceReader) error {
+	verbosity, err := readCompactInt(reader, math.MaxUint8)
+	if err == nil {
+		record.Verbosity = verbosity
+	}
+	return err
+}
+
+func readCompactFunc(record *Record, reader *sliceReader) error {
+	fn, err := readInterned(reader)
+	if err == nil {
+		record.Func = fn
+	}
+	return err
+}
+
+func readCompactMsg(record *Record, reader *sliceReader) error {
+	msg, err := readVarString(reader)
+	if err == nil {
+		record.Msg = msg
+	}
+	return err
+}
+
+func readCompactTemplate(record *Record, reader *sliceReader) error {
+	template, err := readInterned(reader)
+	if err == nil {
+		record.Template = template
+	}
+	return err
+}
+
+func readCompactFormat(record *Record, reader *sliceReader) error {
+	format, err := readInterned(reader)
+	if err == nil {
+		record.format = format
+	}
+	return err
+}
+
+func readCompactContext(record *Record, reader *sliceReader) error {
+	record.Contexts = growContexts(record.Contexts)
+	context := &record.Contexts[len(record.Contexts)-1]
+	if err := readCompactContextBody(context, reader, 0); err != nil {
+		record.Contexts = record.Contexts[:len(record.Contexts)-1]
+		return err
+	}
+	context.Format, record.format = record.format, ""
+	return nil
+}
+
+func readCompactStack(record *Record, reader *sliceReader) error {
+	stack, err := readCompactFrames(record.Stack[:0], reader)
+	if err == nil {
+		record.Stack = stack
+	}
+	return err
+}
+
+func readCompactContextBody(context *Context, reader *sliceReader, depth int) error {
+	key, err := readInterned(reader)
+	if err != nil {
+		return err
+	}
+	kind, err := readValueKind(reader)
+	if err != nil {
+		return err
+	}
+
+	context.reset(key, kind)
+	return readCompactValue(context, reader, depth)
+}
+
+func readCompactValue(context *Context, reader *sliceReader, depth int) error {
+	var err error
+	switch context.Kind {
+	case Bool, Byte, Int8, Uint8, Float32, Float64, Complex64, Complex128:
+		err = readValue(context, reader, depth)
+	case Int16, Int32, Int64, Rune, Time, Duration:
+		var i int64
+		i, err = readVarint(reader)
+		context.bits = uint64(i)
+	case Uint16, Uint32, Uint64, Uintptr:
+		context.bits, err = readUvarint(reader)
+	case String:
+		context.str, err = readVarString(reader)
+	case Bytes:
+		context.bytes, err = readVarBytes(reader)
+	case Error:
+		if context.err == nil {
+			context.err = new(ErrorValue)
+		}
+		err = readCompactErrorValue(context.err, reader, 0)
+	case Array, Object:
+		context.elems, err = readCompactContexts(context.elems[:0], reader, depth+1)
+	}
+	return err
+}
+
+func readCompactContexts(contexts []Context, reader *sliceReader, depth int) ([]Context, error) {
+	if depth > MaxNestingDepth {
+		return nil, newFormatError("nesting too deep")
+	}
+
+	var format string
+	for {
+		kind, err := readFieldKind(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case fieldEnd:
+			return contexts, nil
+		case fieldContext:
+			contexts = growContexts(contexts)
+			context := &contexts[len(contexts)-1]
+			if err := readCompactContextBody(context, reader, depth); err != nil {
+				return nil, err
+			}
+			context.Format, format = format, ""
+		case fieldFormat:
+			if format, err = readInterned(reader); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, newFormatError(fmt.Sprintf("illegal field kind %d in nested value", kind))
+		}
+	}
+}
+
+func readCompactErrorValue(value *ErrorValue, reader *sliceReader, depth int) error {
+	var err error
+
+	if depth > maxErrorDepth {
+		return newFormatError("error chain too deep")
+	}
+	if value.Msg, err = readVarString(reader); err != nil {
+		return err
+	}
+	if value.Type, err = readInterned(reader); err != nil {
+		return err
+	}
+	if value.Stack, err = readCompactFrames(value.Stack[:0], reader); err != nil {
+		return err
+	}
+
+	size, err := readCompactInt(reader, math.MaxUint8)
+	if err != nil {
+		return err
+	}
+	causes := value.Causes[:0]
+	for i := 0; i < size; i++ {
+		if len(causes) < cap(causes) {
+			causes = causes[:len(causes)+1]
+		} else {
+			causes = append(causes, ErrorValue{})
+		}
+		if err := readCompactErrorValue(&causes[i], reader, depth+1); err != nil {
+			return err
+		}
+	}
+	value.Causes = causes
+	return nil
+}
+
+func readCompactFrames(stack []Frame, reader *sliceReader) ([]Frame, error) {
+	size, err := readCompactInt(reader, math.MaxUint16)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < size; i++ {
+		var frame Frame
+		if frame.Func, err = readInterned(reader); err != nil {
+			return nil, err
+		}
+		if frame.File, err = readInterned(reader); err != nil {
+			return nil, err
+		}
+		if frame.Line, err = readCompactInt(reader, math.MaxUint32); err != nil {
+			return nil, err
+		}
+		stack = append(stack, frame)
+	}
+	return stack, nil
+}
+
+// readCompactInt reads a uvarint that must not exceed limit.
+func readCompactInt(reader *sliceReader, limit uint64) (int, error) {
+	u, err := readUvarint(reader)
+	if err != nil {
+		return 0, err
+	}
+	if u > limit {
+		return 0, newFormatError(fmt.Sprintf("value out of range: %d", u))
+	}
+	return int(u), nil
+}
+
+func readInterned(reader *sliceReader) (string, error) {
+	ref, err := readUvarint(reader)
+	if err != nil {
+		return "", err
+	}
+
+	switch ref {
+	case stringInline:
+		return readVarString(reader)
+	case stringDefine:
+		id, err := readUvarint(reader)
+		if err != nil {
+			return "", err
+		}
+		str, err := readVarString(reader)
+		if err != nil {
+			return "", err
+		}
+		return reader.state.define(id, str)
+	default:
+		return reader.state.lookup(ref - stringRefBase)
+	}
+}
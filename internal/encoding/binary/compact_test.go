@@ -0,0 +1,108 @@
+package binary
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFrameChecksum(t *testing.T) {
+	data, offsets := testCompactStream(3)
+	for _, damaged := range []int{0, 1} {
+		damagedData := append([]byte(nil), data...)
+		damagedData[offsets[damaged+1]-1] ^= 1
+
+		reader := NewBytesReader(damagedData)
+		var record Record
+		for i := 0; i < damaged; i++ {
+			if err := reader.Read(&record); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := reader.Read(&record)
+		var corruption *CorruptionError
+		var checksum *ChecksumError
+		if !errors.As(err, &corruption) || !errors.As(err, &checksum) {
+			t.Fatalf("record %d: read = %v, want a checksum error", damaged, err)
+		}
+		if corruption.Offset != int64(offsets[damaged]) {
+			t.Errorf("record %d: corruption at %d, want %d", damaged, corruption.Offset, offsets[damaged])
+		}
+	}
+}
+
+// TestFalseMagic checks that a magic inside a payload is not taken for a
+// record when resyncing.
+func TestFalseMagic(t *testing.T) {
+	var encoder Encoder
+	var data []byte
+	var offsets []int
+	for i := 0; i < DictResetInterval+1; i++ {
+		record := testRecord(i)
+		if i == 1 {
+			msg := append(append([]byte(nil), binaryMagic...), compactVersion)
+			msg = appendUint32(msg, 1)
+			msg = appendUint32(msg, 0)
+			record.Msg = string(append(msg, byte(fieldReset)))
+		}
+		offsets = append(offsets, len(data))
+		data = encoder.AppendRecord(data, record)
+	}
+	data[offsets[1]-1] ^= 1
+
+	reader := NewBytesReader(data)
+	var record Record
+	var corruption *CorruptionError
+	if err := reader.Read(&record); !errors.As(err, &corruption) {
+		t.Fatalf("read = %v, want a CorruptionError", err)
+	}
+	if end := corruption.Offset + corruption.Size; end != int64(offsets[DictResetInterval]) {
+		t.Errorf("resynced at %d, want %d", end, offsets[DictResetInterval])
+	}
+	if err := reader.Read(&record); err != nil || record.Seq != DictResetInterval+1 {
+		t.Errorf("read after resync = %v, seq %d", err, record.Seq)
+	}
+}
+
+func TestTryRead(t *testing.T) {
+	data, _ := testCompactStream(2)
+	garbage := append([]byte("garbage"), binaryMagic[:3]...)
+	reader := NewBytesReader(append(garbage, data...))
+
+	var record Record
+	if err := reader.TryRead(&record); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &record, 0)
+	if err := reader.TryRead(&record); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &record, 1)
+	if err := reader.TryRead(&record); err != EOF {
+		t.Errorf("read at the end = %v, want EOF", err)
+	}
+}
+
+func TestRecordTooLong(t *testing.T) {
+	frame := append([]byte(nil), binaryMagic...)
+	frame = appendUint8(frame, compactVersion)
+	frame = appendUint32(frame, maxRecordSize+1)
+	frame = appendUint32(frame, 0)
+
+	var record Record
+	var formatErr *FormatError
+	if _, err := DecodeRecord(&record, frame); !errors.As(err, &formatErr) {
+		t.Errorf("decode = %v, want a FormatError", err)
+	}
+
+	data, _ := testCompactStream(1)
+	data = appendUint8(data, chainMark)
+	data = appendUvarint(data, maxRecordSize+1)
+	reader := NewBytesReader(data)
+	if err := reader.Read(&record); err != nil {
+		t.Fatal(err)
+	}
+	var corruption *CorruptionError
+	if err := reader.Read(&record); !errors.As(err, &corruption) || !errors.As(err, &formatErr) {
+		t.Errorf("read = %v, want a FormatError", err)
+	}
+}
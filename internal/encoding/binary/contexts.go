@@ -2,7 +2,7 @@ package binary
 
 import (
 	"fmt"
-	"io"
+	"math"
 	"time"
 )
 
@@ -28,6 +28,10 @@ const (
 	String
 	Time
 	Duration
+	Error
+	Array
+	Object
+	Bytes
 
 	valueKindBound
 )
@@ -36,35 +40,6 @@ func (self ValueKind) Legal() bool {
 	return self < valueKindBound
 }
 
-var valueReaders = [...]func(io.Reader) (interface{}, error){
-	Bool:       readBoolValue,
-	Byte:       readByteValue,
-	Rune:       readRuneValue,
-	Int8:       readInt8Value,
-	Int16:      readInt16Value,
-	Int32:      readInt32Value,
-	Int64:      readInt64Value,
-	Uint8:      readUint8Value,
-	Uint16:     readUint16Value,
-	Uint32:     readUint32Value,
-	Uint64:     readUint64Value,
-	Uintptr:    readUintptrValue,
-	Float32:    readFloat32Value,
-	Float64:    readFloat64Value,
-	Complex64:  readComplex64Value,
-	Complex128: readComplex128Value,
-	String:     readStringValue,
-	Time:       readTimeValue,
-	Duration:   readDurationValue,
-}
-
-type Context struct {
-	Key    string
-	Value  interface{}
-	Format string
-	Kind   ValueKind
-}
-
 func AppendBoolContext(dst []byte, key string, value bool) []byte {
 	dst = appendContextMeta(dst, key, Bool)
 	dst = appendBool(dst, value)
@@ -167,6 +142,12 @@ func AppendStringContext(dst []byte, key, value string) []byte {
 	return dst
 }
 
+func AppendBytesContext(dst []byte, key string, value []byte) []byte {
+	dst = appendContextMeta(dst, key, Bytes)
+	dst = appendBytes(dst, value)
+	return dst
+}
+
 func AppendTimeContext(dst []byte, key string, value time.Time) []byte {
 	dst = appendContextMeta(dst, key, Time)
 	dst = appendUint64(dst, uint64(value.UnixNano()))
@@ -179,6 +160,18 @@ func AppendDurationContext(dst []byte, key string, value time.Duration) []byte {
 	return dst
 }
 
+func AppendContext(dst []byte, context *Context) []byte {
+	kind := context.Kind
+	if !kind.Legal() {
+		panic(fmt.Sprintf("glog: illegal value kind %d", kind))
+	}
+	if context.Format != "" {
+		dst = AppendFormat(dst, context.Format)
+	}
+	dst = appendContextMeta(dst, context.Key, kind)
+	return appendValue(dst, context)
+}
+
 func appendContextMeta(dst []byte, key string, kind ValueKind) []byte {
 	dst = appendFieldKind(dst, fieldContext)
 	dst = appendKey(dst, key)
@@ -194,7 +187,40 @@ func appendValueKind(dst []byte, kind ValueKind) []byte {
 	return appendUint8(dst, uint8(kind))
 }
 
-func readContextMeta(reader io.Reader) (string, ValueKind, error) {
+func appendValue(dst []byte, context *Context) []byte {
+	switch context.Kind {
+	case Bool:
+		return appendBool(dst, context.bits != 0)
+	case Byte, Int8, Uint8:
+		return appendUint8(dst, uint8(context.bits))
+	case Int16, Uint16:
+		return appendUint16(dst, uint16(context.bits))
+	case Rune, Int32, Uint32:
+		return appendUint32(dst, uint32(context.bits))
+	case Int64, Uint64, Uintptr, Float64, Time, Duration:
+		return appendUint64(dst, context.bits)
+	case Float32:
+		return appendFloat32(dst, float32(math.Float64frombits(context.bits)))
+	case Complex64:
+		dst = appendFloat32(dst, float32(math.Float64frombits(context.bits)))
+		return appendFloat32(dst, float32(math.Float64frombits(context.imag)))
+	case Complex128:
+		dst = appendUint64(dst, context.bits)
+		return appendUint64(dst, context.imag)
+	case String:
+		return appendString(dst, context.str)
+	case Bytes:
+		return appendBytes(dst, context.bytes)
+	case Error:
+		return appendErrorValue(dst, context.errValue())
+	case Array, Object:
+		return appendContexts(dst, context.elems)
+	default:
+		panic(fmt.Sprintf("glog: illegal value kind %d", context.Kind))
+	}
+}
+
+func readContextMeta(reader *sliceReader) (string, ValueKind, error) {
 	key, err := readKey(reader)
 	if err != nil {
 		return "", valueKindBound, err
@@ -206,11 +232,85 @@ func readContextMeta(reader io.Reader) (string, ValueKind, error) {
 	return key, kind, nil
 }
 
-func readKey(reader io.Reader) (string, error) {
+// readContextBody decodes a context into the given one, reusing the memory of
+// its nested values and error value.
+func readContextBody(context *Context, reader *sliceReader, depth int) error {
+	key, kind, err := readContextMeta(reader)
+	if err != nil {
+		return err
+	}
+
+	context.reset(key, kind)
+	return readValue(context, reader, depth)
+}
+
+func readValue(context *Context, reader *sliceReader, depth int) error {
+	var err error
+	switch context.Kind {
+	case Bool:
+		var b bool
+		b, err = readBool(reader)
+		context.setBool(b)
+	case Byte, Uint8:
+		var u uint8
+		u, err = readUint8(reader)
+		context.bits = uint64(u)
+	case Int8:
+		var u uint8
+		u, err = readUint8(reader)
+		context.bits = uint64(int8(u))
+	case Uint16:
+		var u uint16
+		u, err = readUint16(reader)
+		context.bits = uint64(u)
+	case Int16:
+		var u uint16
+		u, err = readUint16(reader)
+		context.bits = uint64(int16(u))
+	case Uint32:
+		var u uint32
+		u, err = readUint32(reader)
+		context.bits = uint64(u)
+	case Rune, Int32:
+		var u uint32
+		u, err = readUint32(reader)
+		context.bits = uint64(int32(u))
+	case Int64, Uint64, Uintptr, Float64, Time, Duration:
+		context.bits, err = readUint64(reader)
+	case Float32:
+		var f float32
+		f, err = readFloat32(reader)
+		context.bits = math.Float64bits(float64(f))
+	case Complex64:
+		var r, i float32
+		if r, err = readFloat32(reader); err == nil {
+			i, err = readFloat32(reader)
+		}
+		context.setComplex(complex(float64(r), float64(i)))
+	case Complex128:
+		if context.bits, err = readUint64(reader); err == nil {
+			context.imag, err = readUint64(reader)
+		}
+	case String:
+		context.str, err = readString(reader)
+	case Bytes:
+		context.bytes, err = readBytes(reader)
+	case Error:
+		if context.err == nil {
+			context.err = new(ErrorValue)
+		}
+		err = readErrorValue(context.err, reader, 0)
+	case Array, Object:
+		context.elems, err = readContexts(context.elems[:0], reader, depth+1)
+	}
+	return err
+}
+
+func readKey(reader *sliceReader) (string, error) {
 	return readShortString(reader)
 }
 
-func readValueKind(reader io.Reader) (ValueKind, error) {
+func readValueKind(reader *sliceReader) (ValueKind, error) {
 	u, err := readUint8(reader)
 	if err != nil {
 		return valueKindBound, err
@@ -221,95 +321,3 @@ func readValueKind(reader io.Reader) (ValueKind, error) {
 	}
 	return kind, nil
 }
-
-func readBoolValue(reader io.Reader) (interface{}, error) {
-	return readBool(reader)
-}
-
-func readByteValue(reader io.Reader) (interface{}, error) {
-	return readUint8(reader)
-}
-
-func readRuneValue(reader io.Reader) (interface{}, error) {
-	u, err := readUint32(reader)
-	return rune(u), err
-}
-
-func readInt8Value(reader io.Reader) (interface{}, error) {
-	u, err := readUint8(reader)
-	return int8(u), err
-}
-
-func readInt16Value(reader io.Reader) (interface{}, error) {
-	u, err := readUint16(reader)
-	return int16(u), err
-}
-
-func readInt32Value(reader io.Reader) (interface{}, error) {
-	u, err := readUint32(reader)
-	return int32(u), err
-}
-
-func readInt64Value(reader io.Reader) (interface{}, error) {
-	u, err := readUint64(reader)
-	return int64(u), err
-}
-
-func readUint8Value(reader io.Reader) (interface{}, error) {
-	return readUint8(reader)
-}
-
-func readUint16Value(reader io.Reader) (interface{}, error) {
-	return readUint16(reader)
-}
-
-func readUint32Value(reader io.Reader) (interface{}, error) {
-	return readUint32(reader)
-}
-
-func readUint64Value(reader io.Reader) (interface{}, error) {
-	return readUint64(reader)
-}
-
-func readUintptrValue(reader io.Reader) (interface{}, error) {
-	u, err := readUint64(reader)
-	return uintptr(u), err
-}
-
-func readFloat32Value(reader io.Reader) (interface{}, error) {
-	return readFloat32(reader)
-}
-
-func readFloat64Value(reader io.Reader) (interface{}, error) {
-	return readFloat64(reader)
-}
-
-func readComplex64Value(reader io.Reader) (interface{}, error) {
-	return readComplex64(reader)
-}
-
-func readComplex128Value(reader io.Reader) (interface{}, error) {
-	return readComplex128(reader)
-}
-
-func readStringValue(reader io.Reader) (interface{}, error) {
-	return readString(reader)
-}
-
-func readTimeValue(reader io.Reader) (interface{}, error) {
-	return readTime(reader)
-}
-
-func readDurationValue(reader io.Reader) (interface{}, error) {
-	u, err := readUint64(reader)
-	return time.Duration(u), err
-}
-
-func readTime(reader io.Reader) (time.Time, error) {
-	u, err := readUint64(reader)
-	if err != nil {
-		return time.Time{}, err
-	}
-	nano := int64(u)
-	return time.Unix(nano/int64(time.Second), nano%int64(time.Second)), nil
-}
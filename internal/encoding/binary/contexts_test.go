@@ -0,0 +1,38 @@
+package binary
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytesContext(t *testing.T) {
+	payloads := [][]byte{nil, {}, {0, 1, 0xff}, bytes.Repeat([]byte{0xab}, 70000)}
+
+	log := AppendBinaryMeta(nil)
+	for _, payload := range payloads {
+		log = AppendBytesContext(log, "frame", payload)
+	}
+	log = AppendMsg(log, "")
+	log = AppendEnd(log)
+
+	var encoder Encoder
+	compact, err := encoder.Transcode(nil, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{log, compact} {
+		var record Record
+		if _, err := DecodeRecord(&record, data); err != nil {
+			t.Fatal(err)
+		}
+		if len(record.Contexts) != len(payloads) {
+			t.Fatalf("got %d contexts, want %d", len(record.Contexts), len(payloads))
+		}
+		for i, payload := range payloads {
+			context := &record.Contexts[i]
+			if context.Kind != Bytes || !bytes.Equal(context.Bytes(), payload) {
+				t.Errorf("context %d = %d bytes of kind %d, want %d bytes", i, len(context.Bytes()), context.Kind, len(payload))
+			}
+		}
+	}
+}
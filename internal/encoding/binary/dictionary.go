@@ -0,0 +1,149 @@
+package binary
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxDictSize    = 4096
+	maxInternedLen = 1024
+)
+
+const DictResetInterval = 128
+
+// A string that may be interned is stored inline, defined under an explicit
+// id, or referred to as n+stringRefBase.
+const (
+	stringInline = iota
+	stringDefine
+	stringRefBase
+)
+
+// streamState is the dictionary and the base values a version 1 record is
+// decoded against.
+type streamState struct {
+	entries  []dictEntry
+	base     int
+	baseTime int64
+	baseZone int
+	baseSeq  uint64
+	rebase   bool
+	started  bool
+}
+
+type dictEntry struct {
+	str     string
+	defined bool
+}
+
+type stateMark struct {
+	size     int
+	base     int
+	baseTime int64
+	baseZone int
+	baseSeq  uint64
+	rebase   bool
+	started  bool
+}
+
+var errNotStarted = newFormatError("record depends on records preceding the first dictionary restart")
+
+func (this *streamState) mark() stateMark {
+	return stateMark{
+		size:     len(this.entries),
+		base:     this.base,
+		baseTime: this.baseTime,
+		baseZone: this.baseZone,
+		baseSeq:  this.baseSeq,
+		rebase:   this.rebase,
+		started:  this.started,
+	}
+}
+
+// rollback undoes the changes made by a record that failed to decode.
+func (this *streamState) rollback(mark stateMark) {
+	clear(this.entries[mark.size:])
+	this.entries = this.entries[:mark.size]
+	this.base = mark.base
+	this.baseTime = mark.baseTime
+	this.baseZone = mark.baseZone
+	this.baseSeq = mark.baseSeq
+	this.rebase = mark.rebase
+	this.started = mark.started
+}
+
+// commit drops the entries made unreachable by a restart.
+func (this *streamState) commit() {
+	this.rebase = false
+	if this.base > 0 {
+		n := copy(this.entries, this.entries[this.base:])
+		clear(this.entries[n:])
+		this.entries = this.entries[:n]
+		this.base = 0
+	}
+}
+
+// clear forgets the stream, as if nothing had been read.
+func (this *streamState) clear() {
+	this.rollback(stateMark{})
+}
+
+func (this *streamState) reset() {
+	this.base = len(this.entries)
+	this.baseTime = 0
+	this.baseZone = 0
+	this.baseSeq = 0
+	this.rebase = true
+	this.started = true
+}
+
+// time converts a timestamp relative to the restarting record.
+func (this *streamState) time(delta int64) int64 {
+	nano := this.baseTime + delta
+	if this.rebase {
+		this.baseTime = nano
+	}
+	return nano
+}
+
+// zone records the zone offset of a restarting record.
+func (this *streamState) zone(offset int) {
+	if this.rebase {
+		this.baseZone = offset
+	}
+}
+
+func (this *streamState) seq(delta int64) uint64 {
+	seq := this.baseSeq + uint64(delta)
+	if this.rebase {
+		this.baseSeq = seq
+	}
+	return seq
+}
+
+// define adds a copy of str to the dictionary under id.
+func (this *streamState) define(id uint64, str string) (string, error) {
+	size := uint64(len(this.entries) - this.base)
+	if id >= maxDictSize {
+		return "", newFormatError(fmt.Sprintf("string id out of range: %d", id))
+	}
+	if id < size {
+		return "", newFormatError(fmt.Sprintf("string redefined: %d", id))
+	}
+	for ; size < id; size++ {
+		this.entries = append(this.entries, dictEntry{})
+	}
+	str = strings.Clone(str)
+	this.entries = append(this.entries, dictEntry{str: str, defined: true})
+	return str, nil
+}
+
+func (this *streamState) lookup(id uint64) (string, error) {
+	if id < uint64(len(this.entries)-this.base) {
+		if entry := this.entries[this.base+int(id)]; entry.defined {
+			return entry.str, nil
+		}
+	}
+	return "", newFormatError(fmt.Sprintf("undefined string reference %d", id))
+}
@@ -0,0 +1,242 @@
+package binary
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Encoder writes a stream of records in the compact version 1 format, which
+// must be written out in order. The zero value is ready to use.
+type Encoder struct {
+	ids      map[string]uint64
+	interned map[string]string
+	baseTime int64
+	baseZone int
+	baseSeq  uint64
+	records  int
+	record   Record
+	body     []byte
+}
+
+// Reset makes the next record restart the dictionary.
+func (this *Encoder) Reset() {
+	this.records = 0
+}
+
+// Restarting tells whether the next record will restart the dictionary, so
+// that a stream can be decoded from it on, e.g. after seeking to it.
+func (this *Encoder) Restarting() bool {
+	return this.records == 0 || this.records >= DictResetInterval || len(this.ids) >= maxDictSize
+}
+
+// Transcode appends the version 0 or 2 record in log to dst in version 1
+// format.
+func (this *Encoder) Transcode(dst, log []byte) ([]byte, error) {
+	if _, err := DecodeRecord(&this.record, log); err != nil {
+		return dst, err
+	}
+	return this.AppendRecord(dst, &this.record), nil
+}
+
+func (this *Encoder) AppendRecord(dst []byte, record *Record) []byte {
+	if record == nil {
+		panic("glog: encode binary record: record is nil")
+	}
+
+	reset := this.Restarting()
+	this.body = this.appendBody(this.body[:0], record, reset)
+
+	var headerStart int
+	if reset {
+		dst = append(dst, binaryMagic...)
+		headerStart = len(dst)
+		dst = appendUint8(dst, compactVersion)
+		dst = appendUint32(dst, uint32(len(this.body)))
+	} else {
+		headerStart = len(dst)
+		dst = appendUint8(dst, chainMark)
+		dst = appendUvarint(dst, uint64(len(this.body)))
+	}
+	dst = appendUint32(dst, frameChecksum(dst[headerStart:], this.body))
+	return append(dst, this.body...)
+}
+
+func (this *Encoder) appendBody(dst []byte, record *Record, reset bool) []byte {
+	if reset {
+		if this.ids == nil {
+			this.ids = make(map[string]uint64)
+		}
+		clear(this.ids)
+		this.baseTime = 0
+		this.baseZone = 0
+		this.baseSeq = 0
+		this.records = 0
+		dst = appendFieldKind(dst, fieldReset)
+	}
+	this.records++
+
+	dst = appendFieldKind(dst, fieldLevel)
+	dst = appendUint8(dst, uint8(record.Level))
+	if record.Verbosity != 0 {
+		dst = appendFieldKind(dst, fieldVerbosity)
+		dst = appendUvarint(dst, uint64(record.Verbosity))
+	}
+	dst = appendFieldKind(dst, fieldPkg)
+	dst = this.appendString(dst, record.Pkg)
+	if record.File != "" {
+		dst = appendFieldKind(dst, fieldFile)
+		dst = this.appendString(dst, record.File)
+	}
+	if record.Line != 0 {
+		dst = appendFieldKind(dst, fieldLine)
+		dst = appendUvarint(dst, uint64(record.Line))
+	}
+	if record.Func != "" {
+		dst = appendFieldKind(dst, fieldFunc)
+		dst = this.appendString(dst, record.Func)
+	}
+	if record.Mark {
+		dst = appendFieldKind(dst, fieldMark)
+	}
+	for i := range record.Contexts {
+		dst = this.appendContext(dst, &record.Contexts[i])
+	}
+	if len(record.Stack) != 0 {
+		dst = appendFieldKind(dst, fieldStack)
+		dst = this.appendFrames(dst, record.Stack)
+	}
+	if record.Template != "" {
+		dst = appendFieldKind(dst, fieldTemplate)
+		dst = this.appendString(dst, record.Template)
+	}
+	dst = appendFieldKind(dst, fieldMsg)
+	dst = appendVarString(dst, record.Msg)
+
+	nano := record.Time.UnixNano()
+	dst = appendFieldKind(dst, fieldTimestamp)
+	dst = appendVarint(dst, nano-this.baseTime)
+	_, offset := record.Time.Zone()
+	if offset != this.baseZone {
+		dst = appendFieldKind(dst, fieldZone)
+		dst = appendVarint(dst, int64(offset))
+	}
+	if record.Seq != 0 {
+		dst = appendFieldKind(dst, fieldSeq)
+		dst = appendVarint(dst, int64(record.Seq-this.baseSeq))
+	}
+	if reset {
+		this.baseTime = nano
+		this.baseZone = offset
+		this.baseSeq = record.Seq
+	}
+
+	return appendFieldKind(dst, fieldEnd)
+}
+
+// finishFrame fills in the length and checksum of the frame at start.
+func finishFrame(dst []byte, start, bodyStart int) []byte {
+	header := dst[start+sizeOfMagic : bodyStart]
+	putUint32(header[sizeOfVersion:], uint32(len(dst)-bodyStart))
+	putUint32(header[sizeOfVersion+sizeOfLength:], frameChecksum(header[:sizeOfVersion+sizeOfLength], dst[bodyStart:]))
+	return dst
+}
+
+func (this *Encoder) appendString(dst []byte, str string) []byte {
+	if id, ok := this.ids[str]; ok {
+		return appendUvarint(dst, id+stringRefBase)
+	}
+	if len(str) > maxInternedLen || len(this.ids) >= maxDictSize {
+		dst = appendUvarint(dst, stringInline)
+		return appendVarString(dst, str)
+	}
+	id := uint64(len(this.ids))
+	this.ids[this.intern(str)] = id
+	dst = appendUvarint(dst, stringDefine)
+	dst = appendUvarint(dst, id)
+	return appendVarString(dst, str)
+}
+
+// intern returns a copy of str that is kept across dictionary restarts.
+func (this *Encoder) intern(str string) string {
+	if interned, ok := this.interned[str]; ok {
+		return interned
+	}
+	if this.interned == nil {
+		this.interned = make(map[string]string)
+	} else if len(this.interned) >= maxDictSize {
+		clear(this.interned)
+	}
+	interned := strings.Clone(str)
+	this.interned[interned] = interned
+	return interned
+}
+
+func (this *Encoder) appendContext(dst []byte, context *Context) []byte {
+	kind := context.Kind
+	if !kind.Legal() {
+		panic(fmt.Sprintf("glog: illegal value kind %d", kind))
+	}
+	if context.Format != "" {
+		dst = appendFieldKind(dst, fieldFormat)
+		dst = this.appendString(dst, context.Format)
+	}
+	dst = appendFieldKind(dst, fieldContext)
+	dst = this.appendString(dst, context.Key)
+	dst = appendValueKind(dst, kind)
+	return this.appendValue(dst, context)
+}
+
+func (this *Encoder) appendValue(dst []byte, context *Context) []byte {
+	switch context.Kind {
+	case Bool, Byte, Int8, Uint8, Float32, Float64, Complex64, Complex128:
+		return appendValue(dst, context)
+	case Int16, Int32, Int64, Rune, Time, Duration:
+		return appendVarint(dst, int64(context.bits))
+	case Uint16, Uint32, Uint64, Uintptr:
+		return appendUvarint(dst, context.bits)
+	case String:
+		return appendVarString(dst, context.str)
+	case Bytes:
+		return appendVarBytes(dst, context.bytes)
+	case Error:
+		return this.appendErrorValue(dst, context.errValue())
+	case Array, Object:
+		for i := range context.elems {
+			dst = this.appendContext(dst, &context.elems[i])
+		}
+		return AppendNestedEnd(dst)
+	default:
+		panic(fmt.Sprintf("glog: illegal value kind %d", context.Kind))
+	}
+}
+
+func (this *Encoder) appendErrorValue(dst []byte, value ErrorValue) []byte {
+	dst = appendVarString(dst, value.Msg)
+	dst = this.appendString(dst, value.Type)
+	dst = this.appendFrames(dst, value.Stack)
+
+	causes := value.Causes
+	if len(causes) > math.MaxUint8 {
+		causes = causes[:math.MaxUint8]
+	}
+	dst = appendUvarint(dst, uint64(len(causes)))
+	for _, cause := range causes {
+		dst = this.appendErrorValue(dst, cause)
+	}
+	return dst
+}
+
+func (this *Encoder) appendFrames(dst []byte, stack []Frame) []byte {
+	size := len(stack)
+	if size > math.MaxUint16 {
+		size = math.MaxUint16
+	}
+	dst = appendUvarint(dst, uint64(size))
+	for _, frame := range stack[:size] {
+		dst = this.appendString(dst, frame.Func)
+		dst = this.appendString(dst, frame.File)
+		dst = appendUvarint(dst, uint64(frame.Line))
+	}
+	return dst
+}
@@ -0,0 +1,137 @@
+package binary
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// testCompactStream encodes n test records in version 1 format and returns the
+// offsets they begin at.
+func testCompactStream(n int) ([]byte, []int) {
+	var encoder Encoder
+	var data []byte
+	offsets := make([]int, n)
+	for i := 0; i < n; i++ {
+		offsets[i] = len(data)
+		data = encoder.AppendRecord(data, testRecord(i))
+	}
+	return data, offsets
+}
+
+func TestEncoderStream(t *testing.T) {
+	const n = 2*DictResetInterval + 3
+	data, offsets := testCompactStream(n)
+	for i, offset := range offsets {
+		restart := bytes.HasPrefix(data[offset:], binaryMagic)
+		if restart != (i%DictResetInterval == 0) {
+			t.Fatalf("record %d: restart = %v", i, restart)
+		}
+		if !restart && data[offset] != chainMark {
+			t.Fatalf("record %d begins with %#x", i, data[offset])
+		}
+	}
+	if size := len(testStream(n)); len(data) >= size/2 {
+		t.Errorf("compact stream of %d bytes, version 2 of %d", len(data), size)
+	}
+
+	reader := NewBytesReader(data)
+	var record Record
+	for i := 0; i < n; i++ {
+		if err := reader.Read(&record); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		checkRecord(t, &record, i)
+	}
+	if err := reader.Read(&record); err != EOF {
+		t.Errorf("read at the end = %v, want EOF", err)
+	}
+}
+
+func TestEncoderReset(t *testing.T) {
+	var encoder Encoder
+	data := encoder.AppendRecord(nil, testRecord(0))
+	if encoder.Restarting() {
+		t.Error("restarting after the first record")
+	}
+	encoder.Reset()
+	if !encoder.Restarting() {
+		t.Error("not restarting after Reset")
+	}
+	start := len(data)
+	data = encoder.AppendRecord(data, testRecord(1))
+	if !bytes.HasPrefix(data[start:], binaryMagic) {
+		t.Error("record after Reset does not restart the dictionary")
+	}
+
+	var record Record
+	if _, err := DecodeRecord(&record, data[start:]); err != nil {
+		t.Errorf("record after Reset cannot be decoded on its own: %v", err)
+	}
+	chained := encoder.AppendRecord(nil, testRecord(2))
+	if _, err := DecodeRecord(&record, chained); err != errNotStarted {
+		t.Errorf("decoding a chained record on its own = %v, want errNotStarted", err)
+	}
+}
+
+// TestEncoderDamage checks that a damaged record costs no more than the rest
+// of its dictionary block.
+func TestEncoderAllocs(t *testing.T) {
+	// Each run restarts the dictionary at least once.
+	records := make([]*Record, DictResetInterval+1)
+	for i := range records {
+		records[i] = testRecord(i)
+	}
+	var encoder Encoder
+	var data []byte
+	encode := func() {
+		for _, record := range records {
+			data = encoder.AppendRecord(data[:0], record)
+		}
+	}
+	encode()
+	if allocs := testing.AllocsPerRun(10, encode); allocs != 0 {
+		t.Errorf("%v allocs per run, want 0", allocs)
+	}
+}
+
+func TestEncoderDamage(t *testing.T) {
+	const n = 2 * DictResetInterval
+	for _, damaged := range []int{0, 3, DictResetInterval - 1} {
+		data, offsets := testCompactStream(n)
+		data[offsets[damaged+1]-2] ^= 0xff
+
+		reader := NewBytesReader(data)
+		var record Record
+		var read []int
+		var corruptions []*CorruptionError
+		for {
+			err := reader.Read(&record)
+			if err == EOF {
+				break
+			}
+			var corruption *CorruptionError
+			if errors.As(err, &corruption) {
+				corruptions = append(corruptions, corruption)
+				continue
+			}
+			if err != nil {
+				t.Fatalf("damaged %d: %v", damaged, err)
+			}
+			read = append(read, int(record.Seq-1))
+		}
+
+		if len(corruptions) != 1 {
+			t.Fatalf("damaged %d: %d corruptions, want 1", damaged, len(corruptions))
+		}
+		corruption := corruptions[0]
+		if corruption.Offset != int64(offsets[damaged]) ||
+			corruption.Offset+corruption.Size != int64(offsets[DictResetInterval]) {
+			t.Errorf("damaged %d: skipped %d bytes at %d, want up to the next restart at %d",
+				damaged, corruption.Size, corruption.Offset, offsets[DictResetInterval])
+		}
+		if want := damaged + DictResetInterval; len(read) != want {
+			t.Errorf("damaged %d: read %d records, want %d", damaged, len(read), want)
+		}
+	}
+}
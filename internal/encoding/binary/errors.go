@@ -65,4 +65,59 @@ func (this *FormatError) Error() string {
 	return fmt.Sprintf("%s: %s", readingErrPrefix, this.Reason)
 }
 
+type ChecksumError struct {
+	Expected uint32
+	Actual   uint32
+}
+
+func newChecksumError(expected, actual uint32) *ChecksumError {
+	return &ChecksumError{
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+func (this *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: checksum mismatch: expected %08x, actual %08x",
+		readingErrPrefix, this.Expected, this.Actual)
+}
+
+// CorruptionError reports a region of Size bytes at Offset that was skipped.
+type CorruptionError struct {
+	Offset int64
+	Size   int64
+	Err    error
+}
+
+func newCorruptionError(offset, size int64, err error) *CorruptionError {
+	return &CorruptionError{
+		Offset: offset,
+		Size:   size,
+		Err:    err,
+	}
+}
+
+func (this *CorruptionError) Error() string {
+	return fmt.Sprintf("%v (skipped %d bytes at offset %d)", this.Err, this.Size, this.Offset)
+}
+
+func (this *CorruptionError) Unwrap() error {
+	return this.Err
+}
+
+// TruncatedError reports that the input ends within the record at Offset.
+type TruncatedError struct {
+	Offset int64
+}
+
+func newTruncatedError(offset int64) *TruncatedError {
+	return &TruncatedError{
+		Offset: offset,
+	}
+}
+
+func (this *TruncatedError) Error() string {
+	return fmt.Sprintf("%s: incomplete tail at offset %d", readingErrPrefix, this.Offset)
+}
+
 var EOF = errors.New(readingErrPrefix + ": end of file")
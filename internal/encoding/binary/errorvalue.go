@@ -0,0 +1,169 @@
+package binary
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+)
+
+const maxErrorDepth = 16
+
+type ErrorValue struct {
+	Msg    string
+	Type   string
+	Stack  []Frame
+	Causes []ErrorValue
+}
+
+type callersError interface {
+	Callers() []uintptr
+}
+
+func NewErrorValue(err error) ErrorValue {
+	return newErrorValue(err, 0)
+}
+
+func (self ErrorValue) Origin() ErrorValue {
+	origin := self
+	for _, cause := range self.Causes {
+		if o := cause.Origin(); len(o.Stack) != 0 {
+			origin = o
+		}
+	}
+	return origin
+}
+
+func StackFrames(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	stack := make([]Frame, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{
+			Func: frame.Function,
+			File: frame.File,
+			Line: frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+func AppendErrorContext(dst []byte, key string, err error) []byte {
+	dst = appendContextMeta(dst, key, Error)
+	dst = appendErrorValue(dst, NewErrorValue(err))
+	return dst
+}
+
+func newErrorValue(err error, depth int) ErrorValue {
+	if err == nil {
+		return ErrorValue{Msg: "<nil>"}
+	}
+
+	value := ErrorValue{
+		Msg:  errorMsg(err),
+		Type: reflect.TypeOf(err).String(),
+	}
+	if isNilPointer(err) {
+		return value
+	}
+	if e, ok := err.(callersError); ok {
+		value.Stack = StackFrames(e.Callers())
+	}
+	if depth < maxErrorDepth {
+		for _, cause := range unwrapError(err) {
+			if cause != nil {
+				value.Causes = append(value.Causes, newErrorValue(cause, depth+1))
+			}
+		}
+	}
+	return value
+}
+
+// errorMsg calls err.Error(), recovering from panics the way fmt does.
+func errorMsg(err error) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if isNilPointer(err) {
+				msg = "<nil>"
+			} else {
+				msg = fmt.Sprintf("%%!v(PANIC=Error method: %v)", r)
+			}
+		}
+	}()
+	return err.Error()
+}
+
+func isNilPointer(err error) bool {
+	value := reflect.ValueOf(err)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+func unwrapError(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	}
+	return nil
+}
+
+func appendErrorValue(dst []byte, value ErrorValue) []byte {
+	dst = appendString(dst, value.Msg)
+	dst = appendShortString(dst, value.Type)
+	dst = appendFrames(dst, value.Stack)
+
+	causes := value.Causes
+	if len(causes) > math.MaxUint8 {
+		causes = causes[:math.MaxUint8]
+	}
+	dst = appendUint8(dst, uint8(len(causes)))
+	for _, cause := range causes {
+		dst = appendErrorValue(dst, cause)
+	}
+	return dst
+}
+
+func readErrorValue(value *ErrorValue, reader *sliceReader, depth int) error {
+	var err error
+
+	if depth > maxErrorDepth {
+		return newFormatError("error chain too deep")
+	}
+	if value.Msg, err = readString(reader); err != nil {
+		return err
+	}
+	if value.Type, err = readShortString(reader); err != nil {
+		return err
+	}
+	if value.Stack, err = readFrames(value.Stack[:0], reader); err != nil {
+		return err
+	}
+
+	size, err := readUint8(reader)
+	if err != nil {
+		return err
+	}
+	causes := value.Causes[:0]
+	for i := 0; i < int(size); i++ {
+		if len(causes) < cap(causes) {
+			causes = causes[:len(causes)+1]
+		} else {
+			causes = append(causes, ErrorValue{})
+		}
+		if err := readErrorValue(&causes[i], reader, depth+1); err != nil {
+			return err
+		}
+	}
+	value.Causes = causes
+	return nil
+}
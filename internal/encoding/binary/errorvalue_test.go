@@ -0,0 +1,112 @@
+package binary
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type callersErr struct {
+	pcs []uintptr
+}
+
+func (this *callersErr) Error() string {
+	return "traced"
+}
+
+func (this *callersErr) Callers() []uintptr {
+	return this.pcs
+}
+
+type panickyErr struct {
+	msg string
+}
+
+func (this *panickyErr) Error() string {
+	if this.msg == "" {
+		panic("empty message")
+	}
+	return this.msg
+}
+
+func TestErrorValueChain(t *testing.T) {
+	base := errors.New("base")
+	value := NewErrorValue(fmt.Errorf("wrapped: %w", base))
+
+	if value.Msg != "wrapped: base" || value.Type != "*fmt.wrapError" {
+		t.Errorf("value = %q [%s]", value.Msg, value.Type)
+	}
+	if len(value.Causes) != 1 || value.Causes[0].Msg != "base" || value.Causes[0].Type != "*errors.errorString" {
+		t.Errorf("causes = %+v", value.Causes)
+	}
+
+	joined := NewErrorValue(errors.Join(errors.New("a"), errors.New("b")))
+	if len(joined.Causes) != 2 || joined.Causes[0].Msg != "a" || joined.Causes[1].Msg != "b" {
+		t.Errorf("joined causes = %+v", joined.Causes)
+	}
+}
+
+func TestErrorValueStack(t *testing.T) {
+	pcs := make([]uintptr, 8)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	value := NewErrorValue(fmt.Errorf("failed: %w", &callersErr{pcs: pcs}))
+
+	if len(value.Stack) != 0 {
+		t.Errorf("stack attached to the wrapper: %v", value.Stack)
+	}
+	origin := value.Origin()
+	if origin.Msg != "traced" || len(origin.Stack) == 0 ||
+		!strings.HasSuffix(origin.Stack[0].Func, ".TestErrorValueStack") {
+		t.Errorf("origin = %+v", origin)
+	}
+}
+
+func TestErrorValueNil(t *testing.T) {
+	if value := NewErrorValue(nil); value.Msg != "<nil>" || value.Type != "" {
+		t.Errorf("nil error = %+v", value)
+	}
+
+	var typedNil *panickyErr
+	if value := NewErrorValue(typedNil); value.Msg != "<nil>" || value.Type != "*binary.panickyErr" {
+		t.Errorf("nil pointer error = %+v", value)
+	}
+
+	value := NewErrorValue(&panickyErr{})
+	if value.Msg != "%!v(PANIC=Error method: empty message)" {
+		t.Errorf("panicking error = %+v", value)
+	}
+}
+
+func TestErrorContextRoundTrip(t *testing.T) {
+	pcs := make([]uintptr, 8)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	errs := []error{
+		nil,
+		errors.New("plain"),
+		fmt.Errorf("outer: %w", errors.Join(errors.New("a"), &callersErr{pcs: pcs})),
+	}
+
+	log := AppendBinaryMeta(nil)
+	for i, err := range errs {
+		log = AppendErrorContext(log, fmt.Sprint("error", i), err)
+	}
+	log = AppendMsg(log, "")
+	log = AppendEnd(log)
+
+	var record Record
+	if _, err := DecodeRecord(&record, log); err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs {
+		context := record.Context(fmt.Sprint("error", i))
+		if context == nil || context.Kind != Error {
+			t.Fatalf("context %d = %+v", i, context)
+		}
+		if got, want := context.Err(), NewErrorValue(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("error %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package binary
 import (
 	"fmt"
 	"io"
+	"math"
 	"time"
 
 	"github.com/gratonos/glog/pkg/glog/iface"
@@ -20,6 +21,14 @@ const (
 	fieldMsg
 	fieldContext
 	fieldEnd
+	fieldStack
+	fieldFormat
+	fieldTemplate
+	fieldVerbosity
+	fieldFunc
+	fieldReset
+	fieldZone
+	fieldSeq
 
 	fieldKindBound
 )
@@ -30,15 +39,24 @@ func (self fieldKind) Legal() bool {
 
 var binaryMagic = []byte{0x14, 0xf2, 0x79, 0xd3, 0x6b, 0xe7, 0x3d}
 
-const binaryVersion = 0
+// Version 2 is version 0 plus the field kinds from fieldStack and the value
+// kinds from Error on; version 1 is the compact format of the file writer.
+const (
+	legacyVersion  = 0
+	compactVersion = 1
+	binaryVersion  = 2
+)
 
 const (
-	sizeOfMagic   = 7
-	sizeOfVersion = 1
-	sizeOfHeader  = sizeOfMagic + sizeOfVersion
+	sizeOfMagic       = 7
+	sizeOfVersion     = 1
+	sizeOfHeader      = sizeOfMagic + sizeOfVersion
+	sizeOfLength      = 4
+	sizeOfChecksum    = 4
+	sizeOfFrameHeader = sizeOfHeader + sizeOfLength + sizeOfChecksum
 )
 
-var fieldReaders = [...]func(*Record, io.Reader) error{
+var fieldReaders = [...]func(*Record, *sliceReader) error{
 	fieldTimestamp: readTimestamp,
 	fieldLevel:     readLevel,
 	fieldPkg:       readPkg,
@@ -47,6 +65,13 @@ var fieldReaders = [...]func(*Record, io.Reader) error{
 	fieldMark:      readMark,
 	fieldMsg:       readMsg,
 	fieldContext:   readContext,
+	fieldStack:     readStack,
+	fieldFormat:    readFormat,
+	fieldTemplate:  readTemplate,
+	fieldVerbosity: readVerbosity,
+	fieldFunc:      readFunc,
+	fieldZone:      readZone,
+	fieldSeq:       readSeq,
 }
 
 func AppendBinaryMeta(dst []byte) []byte {
@@ -59,9 +84,20 @@ func ResetBuf(dst []byte) []byte {
 	return dst[:sizeOfHeader]
 }
 
+// AppendTime appends a timestamp along with its zone offset, so that it is
+// read back in the zone it was taken in.
 func AppendTime(dst []byte, tm time.Time) []byte {
+	_, offset := tm.Zone()
 	dst = appendFieldKind(dst, fieldTimestamp)
 	dst = appendUint64(dst, uint64(tm.UnixNano()))
+	dst = appendFieldKind(dst, fieldZone)
+	dst = appendUint32(dst, uint32(int32(offset)))
+	return dst
+}
+
+func AppendSeq(dst []byte, seq uint64) []byte {
+	dst = appendFieldKind(dst, fieldSeq)
+	dst = appendUint64(dst, seq)
 	return dst
 }
 
@@ -71,6 +107,15 @@ func AppendLevel(dst []byte, level iface.Level) []byte {
 	return dst
 }
 
+func AppendVerbosity(dst []byte, verbosity int) []byte {
+	if verbosity > math.MaxUint8 {
+		verbosity = math.MaxUint8
+	}
+	dst = appendFieldKind(dst, fieldVerbosity)
+	dst = appendUint8(dst, uint8(verbosity))
+	return dst
+}
+
 func AppendPkg(dst []byte, pkg string) []byte {
 	dst = appendFieldKind(dst, fieldPkg)
 	dst = appendShortString(dst, pkg)
@@ -89,6 +134,12 @@ func AppendLine(dst []byte, line int) []byte {
 	return dst
 }
 
+func AppendFunc(dst []byte, fn string) []byte {
+	dst = appendFieldKind(dst, fieldFunc)
+	dst = appendString(dst, fn)
+	return dst
+}
+
 func AppendMark(dst []byte) []byte {
 	return appendFieldKind(dst, fieldMark)
 }
@@ -99,10 +150,42 @@ func AppendMsg(dst []byte, msg string) []byte {
 	return dst
 }
 
+func AppendStack(dst []byte, stack []Frame) []byte {
+	dst = appendFieldKind(dst, fieldStack)
+	dst = appendFrames(dst, stack)
+	return dst
+}
+
+func AppendFormat(dst []byte, format string) []byte {
+	dst = appendFieldKind(dst, fieldFormat)
+	dst = appendShortString(dst, format)
+	return dst
+}
+
+func AppendTemplate(dst []byte, template string) []byte {
+	dst = appendFieldKind(dst, fieldTemplate)
+	dst = appendString(dst, template)
+	return dst
+}
+
 func AppendEnd(dst []byte) []byte {
 	return appendFieldKind(dst, fieldEnd)
 }
 
+func appendFrames(dst []byte, stack []Frame) []byte {
+	size := len(stack)
+	if size > math.MaxUint16 {
+		size = math.MaxUint16
+	}
+	dst = appendUint16(dst, uint16(size))
+	for _, frame := range stack[:size] {
+		dst = appendString(dst, frame.Func)
+		dst = appendString(dst, frame.File)
+		dst = appendUint32(dst, uint32(frame.Line))
+	}
+	return dst
+}
+
 func appendFieldKind(dst []byte, kind fieldKind) []byte {
 	return appendUint8(dst, uint8(kind))
 }
@@ -122,9 +205,9 @@ func readMagic(reader io.Reader) ([]byte, error) {
 func searchMagic(reader io.Reader) (int64, error) {
 	var n int64
 	var match int
+	buf := make([]byte, 1)
 	for {
-		u8, err := readUint8(reader)
-		if err != nil {
+		if err := read(buf, reader); err != nil {
 			if n == 0 && err.(*IOError).Err == io.EOF {
 				return 0, EOF
 			} else {
@@ -134,22 +217,24 @@ func searchMagic(reader io.Reader) (int64, error) {
 
 		n++
 
-		if binaryMagic[match] == u8 {
+		if binaryMagic[match] == buf[0] {
 			match++
 			if match == sizeOfMagic {
 				return n, nil
 			}
+		} else if buf[0] == binaryMagic[0] {
+			match = 1
 		} else {
 			match = 0
 		}
 	}
 }
 
-func readVersion(reader io.Reader) (uint8, error) {
+func readVersion(reader *sliceReader) (uint8, error) {
 	return readUint8(reader)
 }
 
-func readFields(record *Record, reader io.Reader) error {
+func readFields(record *Record, reader *sliceReader) error {
 	for {
 		kind, err := readFieldKind(reader)
 		if err != nil {
@@ -160,14 +245,17 @@ func readFields(record *Record, reader io.Reader) error {
 			return nil
 		}
 
-		err = fieldReaders[kind](record, reader)
-		if err != nil {
+		fieldReader := fieldReaders[kind]
+		if fieldReader == nil {
+			return newFormatError(fmt.Sprintf("unexpected field kind %d", kind))
+		}
+		if err = fieldReader(record, reader); err != nil {
 			return err
 		}
 	}
 }
 
-func readTimestamp(record *Record, reader io.Reader) error {
+func readTimestamp(record *Record, reader *sliceReader) error {
 	tm, err := readTime(reader)
 	if err == nil {
 		record.Time = tm
@@ -175,7 +263,7 @@ func readTimestamp(record *Record, reader io.Reader) error {
 	return err
 }
 
-func readLevel(record *Record, reader io.Reader) error {
+func readLevel(record *Record, reader *sliceReader) error {
 	u, err := readUint8(reader)
 	if err != nil {
 		return err
@@ -190,7 +278,15 @@ func readLevel(record *Record, reader io.Reader) error {
 	return nil
 }
 
-func readPkg(record *Record, reader io.Reader) error {
+func readVerbosity(record *Record, reader *sliceReader) error {
+	verbosity, err := readUint8(reader)
+	if err == nil {
+		record.Verbosity = int(verbosity)
+	}
+	return err
+}
+
+func readPkg(record *Record, reader *sliceReader) error {
 	pkg, err := readShortString(reader)
 	if err == nil {
 		record.Pkg = pkg
@@ -198,7 +294,7 @@ func readPkg(record *Record, reader io.Reader) error {
 	return err
 }
 
-func readFile(record *Record, reader io.Reader) error {
+func readFile(record *Record, reader *sliceReader) error {
 	file, err := readShortString(reader)
 	if err == nil {
 		record.File = file
@@ -206,7 +302,7 @@ func readFile(record *Record, reader io.Reader) error {
 	return err
 }
 
-func readLine(record *Record, reader io.Reader) error {
+func readLine(record *Record, reader *sliceReader) error {
 	line, err := readUint32(reader)
 	if err == nil {
 		record.Line = int(line)
@@ -214,12 +310,40 @@ func readLine(record *Record, reader io.Reader) error {
 	return err
 }
 
-func readMark(record *Record, _ io.Reader) error {
+func readFunc(record *Record, reader *sliceReader) error {
+	fn, err := readString(reader)
+	if err == nil {
+		record.Func = fn
+	}
+	return err
+}
+
+func readZone(record *Record, reader *sliceReader) error {
+	u, err := readUint32(reader)
+	if err != nil {
+		return err
+	}
+	location, err := fixedZone(int(int32(u)))
+	if err == nil {
+		record.Time = record.Time.In(location)
+	}
+	return err
+}
+
+func readSeq(record *Record, reader *sliceReader) error {
+	seq, err := readUint64(reader)
+	if err == nil {
+		record.Seq = seq
+	}
+	return err
+}
+
+func readMark(record *Record, _ *sliceReader) error {
 	record.Mark = true
 	return nil
 }
 
-func readMsg(record *Record, reader io.Reader) error {
+func readMsg(record *Record, reader *sliceReader) error {
 	msg, err := readString(reader)
 	if err == nil {
 		record.Msg = msg
@@ -227,27 +351,66 @@ func readMsg(record *Record, reader io.Reader) error {
 	return err
 }
 
-func readContext(record *Record, reader io.Reader) error {
-	key, kind, err := readContextMeta(reader)
-	if err != nil {
+func readTemplate(record *Record, reader *sliceReader) error {
+	template, err := readString(reader)
+	if err == nil {
+		record.Template = template
+	}
+	return err
+}
+
+func readContext(record *Record, reader *sliceReader) error {
+	record.Contexts = growContexts(record.Contexts)
+	context := &record.Contexts[len(record.Contexts)-1]
+	if err := readContextBody(context, reader, 0); err != nil {
+		record.Contexts = record.Contexts[:len(record.Contexts)-1]
 		return err
 	}
+	context.Format, record.format = record.format, ""
+	return nil
+}
 
-	value, err := valueReaders[kind](reader)
+func readFormat(record *Record, reader *sliceReader) error {
+	format, err := readShortString(reader)
+	if err == nil {
+		record.format = format
+	}
+	return err
+}
+
+func readStack(record *Record, reader *sliceReader) error {
+	stack, err := readFrames(record.Stack[:0], reader)
+	if err == nil {
+		record.Stack = stack
+	}
+	return err
+}
+
+func readFrames(stack []Frame, reader *sliceReader) ([]Frame, error) {
+	size, err := readUint16(reader)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	context := Context{
-		Key:   key,
-		Kind:  kind,
-		Value: value,
+	for i := 0; i < int(size); i++ {
+		var frame Frame
+		if frame.Func, err = readString(reader); err != nil {
+			return nil, err
+		}
+		if frame.File, err = readString(reader); err != nil {
+			return nil, err
+		}
+		line, err := readUint32(reader)
+		if err != nil {
+			return nil, err
+		}
+		frame.Line = int(line)
+		stack = append(stack, frame)
 	}
-	record.Contexts = append(record.Contexts, context)
-	return nil
+	return stack, nil
 }
 
-func readFieldKind(reader io.Reader) (fieldKind, error) {
+func readFieldKind(reader *sliceReader) (fieldKind, error) {
 	u, err := readUint8(reader)
 	if err != nil {
 		return fieldKindBound, err
@@ -260,3 +423,11 @@ func readFieldKind(reader io.Reader) (fieldKind, error) {
 
 	return kind, nil
 }
+
+func readTime(reader *sliceReader) (time.Time, error) {
+	u, err := readUint64(reader)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(u)), nil
+}
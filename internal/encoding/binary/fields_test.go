@@ -0,0 +1,82 @@
+package binary
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestStackRoundTrip(t *testing.T) {
+	stack := []Frame{
+		{Func: "main.main", File: "/src/main.go", Line: 12},
+		{Func: "runtime.main", File: "/go/src/runtime/proc.go", Line: 271},
+	}
+	record := Record{
+		Time:  time.Unix(0, 1e9).In(time.FixedZone("", 3600)),
+		Level: iface.Error,
+		Pkg:   "main",
+		Msg:   "failed",
+		Stack: stack,
+	}
+	log := AppendRecord(nil, &record)
+	if log[sizeOfMagic] != binaryVersion {
+		t.Fatalf("version = %d, want %d", log[sizeOfMagic], binaryVersion)
+	}
+
+	var decoded Record
+	if _, err := DecodeRecord(&decoded, log); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Stack, stack) {
+		t.Errorf("stack = %v, want %v", decoded.Stack, stack)
+	}
+
+	var encoder Encoder
+	compact, err := encoder.Transcode(nil, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeRecord(&decoded, compact); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Stack, stack) {
+		t.Errorf("compact stack = %v, want %v", decoded.Stack, stack)
+	}
+}
+
+func TestLegacyVersion(t *testing.T) {
+	// A record as the original version 0 encoder wrote it.
+	log := append([]byte(nil), binaryMagic...)
+	log = appendUint8(log, legacyVersion)
+	log = appendFieldKind(log, fieldLevel)
+	log = appendUint8(log, uint8(iface.Info))
+	log = appendFieldKind(log, fieldPkg)
+	log = appendShortString(log, "main")
+	log = appendFieldKind(log, fieldMsg)
+	log = appendString(log, "hello")
+	log = appendFieldKind(log, fieldTimestamp)
+	log = appendUint64(log, 1e9)
+	log = appendFieldKind(log, fieldEnd)
+
+	var record Record
+	n, err := DecodeRecord(&record, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(log) || record.Msg != "hello" || record.Pkg != "main" || record.Time.UnixNano() != 1e9 {
+		t.Errorf("decoded %d bytes into %+v", n, record)
+	}
+}
+
+func TestUnknownVersion(t *testing.T) {
+	log := AppendRecord(nil, &Record{Pkg: "main", Level: iface.Info})
+	log[sizeOfMagic] = binaryVersion + 1
+
+	var record Record
+	_, err := DecodeRecord(&record, log)
+	if versionErr, ok := err.(*VersionError); !ok || versionErr.Version != binaryVersion+1 {
+		t.Errorf("err = %v, want version error", err)
+	}
+}
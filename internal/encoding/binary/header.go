@@ -0,0 +1,144 @@
+package binary
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// A file header is framed like a version 1 record, marked by headerMark.
+const headerMark = 0x80
+
+var errNotHeader = errors.New("not a file header")
+
+// FileHeader describes the process that wrote a log file.
+type FileHeader struct {
+	Time       time.Time
+	Hostname   string
+	Pid        int
+	Executable string
+	GoVersion  string
+	Logger     string
+	Version    int
+	Zone       string
+	ZoneOffset int
+	Previous   string
+}
+
+// Location returns the time zone of the process that wrote the file.
+func (this *FileHeader) Location() *time.Location {
+	return time.FixedZone(this.Zone, this.ZoneOffset)
+}
+
+// AppendHeader appends a file header announcing the records the Encoder writes.
+func (this *Encoder) AppendHeader(dst []byte, header *FileHeader) []byte {
+	if header == nil {
+		panic("glog: encode binary header: header is nil")
+	}
+
+	start := len(dst)
+	dst = append(dst, binaryMagic...)
+	dst = appendUint8(dst, headerMark)
+	dst = appendUint32(dst, 0)
+	dst = appendUint32(dst, 0)
+	bodyStart := len(dst)
+
+	dst = appendVarint(dst, header.Time.UnixNano())
+	dst = appendVarString(dst, header.Hostname)
+	dst = appendUvarint(dst, uint64(header.Pid))
+	dst = appendVarString(dst, header.Executable)
+	dst = appendVarString(dst, header.GoVersion)
+	dst = appendVarString(dst, header.Logger)
+	dst = appendUint8(dst, compactVersion)
+	dst = appendVarString(dst, header.Zone)
+	dst = appendVarint(dst, int64(header.ZoneOffset))
+	dst = appendVarString(dst, header.Previous)
+	return finishFrame(dst, start, bodyStart)
+}
+
+func isHeaderFrame(data []byte) bool {
+	return len(data) >= sizeOfHeader &&
+		bytes.Equal(data[:sizeOfMagic], binaryMagic) &&
+		data[sizeOfMagic] == headerMark
+}
+
+// decodeHeader decodes the file header at the beginning of data, copying strings.
+func decodeHeader(header *FileHeader, data []byte) (int, error) {
+	if len(data) < sizeOfHeader {
+		return 0, errShortData
+	}
+	if !isHeaderFrame(data) {
+		return 0, errNotHeader
+	}
+
+	reader := sliceReader{data: data, pos: sizeOfHeader}
+	body, err := readFrame(&reader)
+	if err != nil {
+		return 0, err
+	}
+	// Keep the previous header if this one turns out to be damaged.
+	var decoded FileHeader
+	if err := readHeaderBody(&decoded, &sliceReader{data: body}); err != nil {
+		if err == errShortData {
+			err = newFormatError("file header ends prematurely")
+		}
+		return 0, err
+	}
+	*header = decoded
+	return reader.pos, nil
+}
+
+func readHeaderBody(header *FileHeader, reader *sliceReader) error {
+	var err error
+	var nano int64
+	var pid uint64
+	var version uint8
+	var offset int64
+
+	if nano, err = readVarint(reader); err != nil {
+		return err
+	}
+	if header.Hostname, err = readHeaderString(reader); err != nil {
+		return err
+	}
+	if pid, err = readUvarint(reader); err != nil {
+		return err
+	}
+	if header.Executable, err = readHeaderString(reader); err != nil {
+		return err
+	}
+	if header.GoVersion, err = readHeaderString(reader); err != nil {
+		return err
+	}
+	if header.Logger, err = readHeaderString(reader); err != nil {
+		return err
+	}
+	if version, err = readUint8(reader); err != nil {
+		return err
+	}
+	if header.Zone, err = readHeaderString(reader); err != nil {
+		return err
+	}
+	if offset, err = readVarint(reader); err != nil {
+		return err
+	}
+	if header.Previous, err = readHeaderString(reader); err != nil {
+		return err
+	}
+	if pid > math.MaxInt32 || offset < -maxZoneOffset || offset > maxZoneOffset {
+		return newFormatError("file header out of range")
+	}
+
+	header.Time = time.Unix(0, nano)
+	header.Pid = int(pid)
+	header.Version = int(version)
+	header.ZoneOffset = int(offset)
+	return nil
+}
+
+func readHeaderString(reader *sliceReader) (string, error) {
+	str, err := readVarString(reader)
+	return strings.Clone(str), err
+}
@@ -0,0 +1,108 @@
+package binary
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testHeader() *FileHeader {
+	return &FileHeader{
+		Time:       time.Unix(1700000000, 123),
+		Hostname:   "host",
+		Pid:        4242,
+		Executable: "/usr/bin/app",
+		GoVersion:  "go1.23.0",
+		Logger:     "svc",
+		Zone:       "CET",
+		ZoneOffset: 3600,
+		Previous:   "/var/log/app/2024_0101/000000.000000000.log.bin",
+	}
+}
+
+func TestHeader(t *testing.T) {
+	var encoder Encoder
+	data := encoder.AppendHeader(nil, testHeader())
+	data = encoder.AppendRecord(data, testRecord(0))
+
+	reader := NewBytesReader(data)
+	if reader.Header() != nil {
+		t.Error("header before reading one")
+	}
+	header := reader.ReadHeader()
+	want := testHeader()
+	want.Version = compactVersion
+	if header == nil || !reflect.DeepEqual(*header, *want) {
+		t.Fatalf("header = %+v, want %+v", header, want)
+	}
+	if _, offset := header.Time.In(header.Location()).Zone(); offset != 3600 {
+		t.Errorf("zone offset of the location = %d", offset)
+	}
+
+	var record Record
+	if err := reader.Read(&record); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &record, 0)
+}
+
+// TestHeaderInStream checks that Read takes in headers met on the way, as in
+// files concatenated.
+func TestHeaderInStream(t *testing.T) {
+	var encoder Encoder
+	data := encoder.AppendRecord(nil, testRecord(0))
+	encoder.Reset()
+	second := testHeader()
+	second.Logger = "second"
+	data = encoder.AppendHeader(data, second)
+	data = encoder.AppendRecord(data, testRecord(1))
+
+	reader := NewBytesReader(data)
+	if reader.ReadHeader() != nil {
+		t.Error("header read where there is none")
+	}
+	var record Record
+	for i := 0; i < 2; i++ {
+		if err := reader.Read(&record); err != nil {
+			t.Fatal(err)
+		}
+		checkRecord(t, &record, i)
+	}
+	if header := reader.Header(); header == nil || header.Logger != "second" {
+		t.Errorf("header = %+v, want the second one", header)
+	}
+}
+
+func TestHeaderDamaged(t *testing.T) {
+	var encoder Encoder
+	first := encoder.AppendHeader(nil, testHeader())
+	damaged := testHeader()
+	damaged.Logger = "damaged"
+	data := encoder.AppendHeader(append([]byte(nil), first...), damaged)
+	data[len(data)-1] ^= 1
+
+	reader := NewBytesReader(data)
+	reader.ReadHeader()
+	if header := reader.ReadHeader(); header == nil || header.Logger != "svc" {
+		t.Errorf("header = %+v, want the undamaged one kept", header)
+	}
+	if offset := reader.Offset(); offset != int64(len(first)) {
+		t.Errorf("offset = %d, want %d at the damaged header", offset, len(first))
+	}
+}
+
+func TestHeaderTrailingFields(t *testing.T) {
+	var encoder Encoder
+	data := encoder.AppendHeader(nil, testHeader())
+	// A field added by a later version is appended to the body.
+	start := bytes.Index(data, binaryMagic)
+	data = append(data, 42)
+	data = finishFrame(data, start, sizeOfFrameHeader)
+
+	var header FileHeader
+	n, err := decodeHeader(&header, data)
+	if err != nil || n != len(data) || header.Logger != "svc" {
+		t.Errorf("decodeHeader = %d, %v, logger %q", n, err, header.Logger)
+	}
+}
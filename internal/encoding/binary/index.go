@@ -0,0 +1,71 @@
+package binary
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// An index maps timestamps to the offsets of records restarting the dictionary.
+var indexMagic = []byte{0x14, 0xf2, 0x79, 0xd3, 0x6b, 0xe7, 0x49}
+
+const (
+	indexVersion     = 0
+	sizeOfIndexEntry = 16
+)
+
+type IndexEntry struct {
+	Time   time.Time
+	Offset int64
+}
+
+func AppendIndexHeader(dst []byte) []byte {
+	dst = append(dst, indexMagic...)
+	return appendUint8(dst, indexVersion)
+}
+
+func AppendIndexEntry(dst []byte, tm time.Time, offset int64) []byte {
+	dst = appendUint64(dst, uint64(tm.UnixNano()))
+	return appendUint64(dst, uint64(offset))
+}
+
+// ParseIndex parses an index, ignoring a partial entry at the end.
+func ParseIndex(data []byte) ([]IndexEntry, error) {
+	reader := sliceReader{data: data}
+	magic, err := reader.next(sizeOfMagic)
+	if err != nil {
+		return nil, newFormatError("index too short")
+	}
+	if string(magic) != string(indexMagic) {
+		return nil, newFormatError(fmt.Sprintf("unmatched index magic %02x", magic))
+	}
+	version, err := readUint8(&reader)
+	if err != nil {
+		return nil, newFormatError("index too short")
+	}
+	if version != indexVersion {
+		return nil, newFormatError(fmt.Sprintf("unsupported index version %d", version))
+	}
+
+	entries := make([]IndexEntry, 0, (len(data)-reader.pos)/sizeOfIndexEntry)
+	for len(data)-reader.pos >= sizeOfIndexEntry {
+		nano, _ := readUint64(&reader)
+		offset, _ := readUint64(&reader)
+		entries = append(entries, IndexEntry{
+			Time:   time.Unix(0, int64(nano)),
+			Offset: int64(offset),
+		})
+	}
+	return entries, nil
+}
+
+// SearchIndex returns the offset of the last entry before tm, or 0 if none.
+func SearchIndex(entries []IndexEntry, tm time.Time) int64 {
+	i := sort.Search(len(entries), func(i int) bool {
+		return !entries[i].Time.Before(tm)
+	})
+	if i == 0 {
+		return 0
+	}
+	return entries[i-1].Offset
+}
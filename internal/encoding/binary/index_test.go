@@ -0,0 +1,108 @@
+package binary
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIndex(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	data := AppendIndexHeader(nil)
+	for i := 0; i < 4; i++ {
+		data = AppendIndexEntry(data, base.Add(time.Duration(i)*time.Second), int64(100*(i+1)))
+	}
+	// A partial entry being written is ignored.
+	data = append(data, 1, 2, 3)
+
+	entries, err := ParseIndex(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || !entries[3].Time.Equal(base.Add(3*time.Second)) || entries[3].Offset != 400 {
+		t.Fatalf("entries = %v", entries)
+	}
+
+	tests := []struct {
+		tm     time.Time
+		offset int64
+	}{
+		{base.Add(-time.Second), 0},
+		{base, 0},
+		{base.Add(time.Millisecond), 100},
+		// Records in the block before the entry may have been logged at
+		// its time too.
+		{base.Add(2 * time.Second), 200},
+		{base.Add(2*time.Second + 1), 300},
+		{base.Add(time.Hour), 400},
+	}
+	for _, test := range tests {
+		if offset := SearchIndex(entries, test.tm); offset != test.offset {
+			t.Errorf("SearchIndex(%v) = %d, want %d", test.tm.Sub(base), offset, test.offset)
+		}
+	}
+	if offset := SearchIndex(nil, base); offset != 0 {
+		t.Errorf("SearchIndex of no entries = %d", offset)
+	}
+}
+
+func TestIndexSharedTimestamp(t *testing.T) {
+	// Records 1 to 3 share a timestamp, and record 2 restarts the dictionary,
+	// so the index points at it.
+	tm := time.Unix(1700000000, 0)
+	times := []time.Time{tm.Add(-time.Second), tm, tm, tm}
+	var encoder Encoder
+	var data []byte
+	index := AppendIndexHeader(nil)
+	for i, recordTime := range times {
+		if i == 2 {
+			encoder.Reset()
+		}
+		if encoder.Restarting() {
+			index = AppendIndexEntry(index, recordTime, int64(len(data)))
+		}
+		record := testRecord(i)
+		record.Time = recordTime
+		data = encoder.AppendRecord(data, record)
+	}
+	entries, err := ParseIndex(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := NewBytesReader(data)
+	if err := reader.SeekTo(SearchIndex(entries, tm)); err != nil {
+		t.Fatal(err)
+	}
+	var record Record
+	found := 0
+	for {
+		err := reader.Read(&record)
+		if err == EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !record.Time.Before(tm) {
+			found++
+		}
+	}
+	if found != 3 {
+		t.Errorf("found %d records at the time, want 3", found)
+	}
+}
+
+func TestParseIndexErrors(t *testing.T) {
+	var formatErr *FormatError
+	for _, data := range [][]byte{
+		nil,
+		indexMagic,
+		append([]byte("not an index"), 0),
+		append(append([]byte(nil), indexMagic...), indexVersion+1),
+	} {
+		if _, err := ParseIndex(data); !errors.As(err, &formatErr) {
+			t.Errorf("ParseIndex(%x) = %v, want a FormatError", data, err)
+		}
+	}
+}
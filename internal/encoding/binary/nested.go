@@ -0,0 +1,70 @@
+package binary
+
+import "fmt"
+
+const MaxNestingDepth = 32
+
+type (
+	ArrayValue  []Context
+	ObjectValue []Context
+)
+
+func AppendArrayBegin(dst []byte, key string) []byte {
+	return appendContextMeta(dst, key, Array)
+}
+
+func AppendObjectBegin(dst []byte, key string) []byte {
+	return appendContextMeta(dst, key, Object)
+}
+
+func AppendNestedEnd(dst []byte) []byte {
+	return appendFieldKind(dst, fieldEnd)
+}
+
+func appendContexts(dst []byte, contexts []Context) []byte {
+	for i := range contexts {
+		dst = AppendContext(dst, &contexts[i])
+	}
+	return AppendNestedEnd(dst)
+}
+
+func readContexts(contexts []Context, reader *sliceReader, depth int) ([]Context, error) {
+	if depth > MaxNestingDepth {
+		return nil, newFormatError("nesting too deep")
+	}
+
+	var format string
+	for {
+		kind, err := readFieldKind(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case fieldEnd:
+			return contexts, nil
+		case fieldContext:
+			contexts = growContexts(contexts)
+			context := &contexts[len(contexts)-1]
+			if err := readContextBody(context, reader, depth); err != nil {
+				return nil, err
+			}
+			context.Format, format = format, ""
+		case fieldFormat:
+			if format, err = readShortString(reader); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, newFormatError(fmt.Sprintf("illegal field kind %d in nested value", kind))
+		}
+	}
+}
+
+// growContexts extends contexts by one element, reusing the element left
+// beyond its length by a previous decoding if there is one.
+func growContexts(contexts []Context) []Context {
+	if n := len(contexts); n < cap(contexts) {
+		return contexts[:n+1]
+	}
+	return append(contexts, Context{})
+}
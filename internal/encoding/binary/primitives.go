@@ -5,8 +5,21 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"unsafe"
 )
 
+const maxBytesSize = 1 << 20
+
+// sliceReader decodes primitives from a byte slice without copying.
+type sliceReader struct {
+	data  []byte
+	pos   int
+	need  int
+	state *streamState
+}
+
+var errShortData = newIOError(io.ErrUnexpectedEOF)
+
 func appendBool(dst []byte, b bool) []byte {
 	if b {
 		return appendUint8(dst, 1)
@@ -20,21 +33,23 @@ func appendUint8(dst []byte, u uint8) []byte {
 }
 
 func appendUint16(dst []byte, u uint16) []byte {
-	buf := make([]byte, 2)
-	binary.LittleEndian.PutUint16(buf, u)
-	return append(dst, buf...)
+	return binary.LittleEndian.AppendUint16(dst, u)
 }
 
 func appendUint32(dst []byte, u uint32) []byte {
-	buf := make([]byte, 4)
-	binary.LittleEndian.PutUint32(buf, u)
-	return append(dst, buf...)
+	return binary.LittleEndian.AppendUint32(dst, u)
 }
 
 func appendUint64(dst []byte, u uint64) []byte {
-	buf := make([]byte, 8)
-	binary.LittleEndian.PutUint64(buf, u)
-	return append(dst, buf...)
+	return binary.LittleEndian.AppendUint64(dst, u)
+}
+
+func putUint32(dst []byte, u uint32) {
+	binary.LittleEndian.PutUint32(dst, u)
+}
+
+func getUint32(src []byte) uint32 {
+	return binary.LittleEndian.Uint32(src)
 }
 
 func appendFloat32(dst []byte, f float32) []byte {
@@ -67,6 +82,16 @@ func appendString(dst []byte, str string) []byte {
 	return dst
 }
 
+func appendBytes(dst []byte, b []byte) []byte {
+	size := len(b)
+	if size > maxBytesSize {
+		size = maxBytesSize
+	}
+	dst = appendUint32(dst, uint32(size))
+	dst = append(dst, b[:size]...)
+	return dst
+}
+
 func appendShortString(dst []byte, str string) []byte {
 	size := len(str)
 	if size > math.MaxUint8 {
@@ -77,7 +102,27 @@ func appendShortString(dst []byte, str string) []byte {
 	return dst
 }
 
-func readBool(reader io.Reader) (bool, error) {
+func appendUvarint(dst []byte, u uint64) []byte {
+	return binary.AppendUvarint(dst, u)
+}
+
+func appendVarint(dst []byte, i int64) []byte {
+	return binary.AppendVarint(dst, i)
+}
+
+func appendVarString(dst []byte, str string) []byte {
+	dst = appendUvarint(dst, uint64(len(str)))
+	dst = append(dst, str...)
+	return dst
+}
+
+func appendVarBytes(dst []byte, b []byte) []byte {
+	dst = appendUvarint(dst, uint64(len(b)))
+	dst = append(dst, b...)
+	return dst
+}
+
+func readBool(reader *sliceReader) (bool, error) {
 	u, err := readUint8(reader)
 	if err != nil {
 		return false, err
@@ -85,50 +130,42 @@ func readBool(reader io.Reader) (bool, error) {
 	if u > 1 {
 		return false, newFormatError(fmt.Sprintf("illegal bool value: %d", u))
 	}
-	if u == 1 {
-		return true, nil
-	} else {
-		return false, nil
-	}
+	return u == 1, nil
 }
 
-func readUint8(reader io.Reader) (uint8, error) {
-	buf := make([]byte, 1)
-	if err := read(buf, reader); err != nil {
+func readUint8(reader *sliceReader) (uint8, error) {
+	buf, err := reader.next(1)
+	if err != nil {
 		return 0, err
-	} else {
-		return buf[0], nil
 	}
+	return buf[0], nil
 }
 
-func readUint16(reader io.Reader) (uint16, error) {
-	buf := make([]byte, 2)
-	if err := read(buf, reader); err != nil {
+func readUint16(reader *sliceReader) (uint16, error) {
+	buf, err := reader.next(2)
+	if err != nil {
 		return 0, err
-	} else {
-		return binary.LittleEndian.Uint16(buf), nil
 	}
+	return binary.LittleEndian.Uint16(buf), nil
 }
 
-func readUint32(reader io.Reader) (uint32, error) {
-	buf := make([]byte, 4)
-	if err := read(buf, reader); err != nil {
+func readUint32(reader *sliceReader) (uint32, error) {
+	buf, err := reader.next(4)
+	if err != nil {
 		return 0, err
-	} else {
-		return binary.LittleEndian.Uint32(buf), nil
 	}
+	return binary.LittleEndian.Uint32(buf), nil
 }
 
-func readUint64(reader io.Reader) (uint64, error) {
-	buf := make([]byte, 8)
-	if err := read(buf, reader); err != nil {
+func readUint64(reader *sliceReader) (uint64, error) {
+	buf, err := reader.next(8)
+	if err != nil {
 		return 0, err
-	} else {
-		return binary.LittleEndian.Uint64(buf), nil
 	}
+	return binary.LittleEndian.Uint64(buf), nil
 }
 
-func readFloat32(reader io.Reader) (float32, error) {
+func readFloat32(reader *sliceReader) (float32, error) {
 	u, err := readUint32(reader)
 	if err != nil {
 		return 0.0, err
@@ -136,7 +173,7 @@ func readFloat32(reader io.Reader) (float32, error) {
 	return math.Float32frombits(u), nil
 }
 
-func readFloat64(reader io.Reader) (float64, error) {
+func readFloat64(reader *sliceReader) (float64, error) {
 	u, err := readUint64(reader)
 	if err != nil {
 		return 0.0, err
@@ -144,52 +181,97 @@ func readFloat64(reader io.Reader) (float64, error) {
 	return math.Float64frombits(u), nil
 }
 
-func readComplex64(reader io.Reader) (complex64, error) {
-	r, err := readFloat32(reader)
+func readString(reader *sliceReader) (string, error) {
+	size, err := readUint16(reader)
 	if err != nil {
-		return 0.0, err
+		return "", err
 	}
-	i, err := readFloat32(reader)
+	return readStr(reader, int(size))
+}
+
+func readShortString(reader *sliceReader) (string, error) {
+	size, err := readUint8(reader)
 	if err != nil {
-		return 0.0, err
+		return "", err
 	}
-	return complex(r, i), nil
+	return readStr(reader, int(size))
 }
 
-func readComplex128(reader io.Reader) (complex128, error) {
-	r, err := readFloat64(reader)
+func readBytes(reader *sliceReader) ([]byte, error) {
+	size, err := readUint32(reader)
 	if err != nil {
-		return 0.0, err
+		return nil, err
 	}
-	i, err := readFloat64(reader)
-	if err != nil {
-		return 0.0, err
+	if size > maxBytesSize {
+		return nil, newFormatError(fmt.Sprintf("bytes too long: %d", size))
 	}
-	return complex(r, i), nil
+	return reader.next(int(size))
 }
 
-func readString(reader io.Reader) (string, error) {
-	size, err := readUint16(reader)
+func readUvarint(reader *sliceReader) (uint64, error) {
+	u, n := binary.Uvarint(reader.data[reader.pos:])
+	if n > 0 {
+		reader.pos += n
+		return u, nil
+	}
+	if n < 0 {
+		return 0, newFormatError("varint overflows 64 bits")
+	}
+	// A varint is at most binary.MaxVarintLen64 bytes long, so ask for one
+	// more byte at a time until it is complete.
+	reader.need = 1
+	return 0, errShortData
+}
+
+func readVarint(reader *sliceReader) (int64, error) {
+	u, err := readUvarint(reader)
+	i := int64(u >> 1)
+	if u&1 != 0 {
+		i = ^i
+	}
+	return i, err
+}
+
+func readVarString(reader *sliceReader) (string, error) {
+	size, err := readUvarint(reader)
 	if err != nil {
 		return "", err
 	}
-	return readStr(reader, uint(size))
+	if size > maxBytesSize {
+		return "", newFormatError(fmt.Sprintf("string too long: %d", size))
+	}
+	return readStr(reader, int(size))
 }
 
-func readShortString(reader io.Reader) (string, error) {
-	size, err := readUint8(reader)
+func readVarBytes(reader *sliceReader) ([]byte, error) {
+	size, err := readUvarint(reader)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if size > maxBytesSize {
+		return nil, newFormatError(fmt.Sprintf("bytes too long: %d", size))
 	}
-	return readStr(reader, uint(size))
+	return reader.next(int(size))
 }
 
-func readStr(reader io.Reader, size uint) (string, error) {
-	buf := make([]byte, size)
-	if err := read(buf, reader); err != nil {
+func readStr(reader *sliceReader, size int) (string, error) {
+	buf, err := reader.next(size)
+	if err != nil || size == 0 {
 		return "", err
 	}
-	return string(buf), nil
+	return unsafe.String(&buf[0], size), nil
+}
+
+// next returns the following n bytes, or errShortData if fewer are left.
+func (this *sliceReader) next(n int) ([]byte, error) {
+	end := this.pos + n
+	if end > len(this.data) {
+		this.need = end - len(this.data)
+		return nil, errShortData
+	}
+	buf := this.data[this.pos:end:end]
+	this.pos = end
+	return buf, nil
 }
 
 func read(buf []byte, reader io.Reader) error {
@@ -0,0 +1,279 @@
+package binary
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+const (
+	readerBufSize      = 64 << 10
+	maxEmptyReads      = 100
+	maxPartialMagicLen = sizeOfMagic - 1
+)
+
+// Reader decodes a stream of records in place, so a record read is only valid
+// until the next read. Reading again after the end picks up appended data.
+type Reader struct {
+	reader    io.Reader
+	buf       []byte
+	pos       int
+	offset    int64
+	err       error
+	state     streamState
+	header    FileHeader
+	hasHeader bool
+}
+
+func NewReader(reader io.Reader) *Reader {
+	if reader == nil {
+		panic(readingErrPrefix + ": reader is nil")
+	}
+	return &Reader{
+		reader: reader,
+		buf:    make([]byte, 0, readerBufSize),
+	}
+}
+
+func NewBytesReader(data []byte) *Reader {
+	return &Reader{
+		buf: data,
+		err: io.EOF,
+	}
+}
+
+// Offset returns the offset in the input of the next byte to be decoded.
+func (this *Reader) Offset() int64 {
+	return this.offset + int64(this.pos)
+}
+
+// Header returns the last file header read, or nil if none has been read.
+func (this *Reader) Header() *FileHeader {
+	if !this.hasHeader {
+		return nil
+	}
+	return &this.header
+}
+
+// ReadHeader reads the file header at the current position, if any, and
+// returns Header().
+func (this *Reader) ReadHeader() *FileHeader {
+	for {
+		n, err := decodeHeader(&this.header, this.buf[this.pos:])
+		switch {
+		case err == nil:
+			this.pos += n
+			this.hasHeader = true
+			return &this.header
+		case err == errShortData && this.err == nil:
+			this.fill()
+		default:
+			return this.Header()
+		}
+	}
+}
+
+// SeekTo moves to offset, where a record that can be decoded on its own must
+// begin. Unless the input is a byte slice, it must implement io.Seeker.
+func (this *Reader) SeekTo(offset int64) error {
+	if this.reader == nil {
+		if offset < 0 || offset > int64(len(this.buf)) {
+			return newIOError(errors.New("seek out of range"))
+		}
+		this.pos = int(offset)
+	} else {
+		seeker, ok := this.reader.(io.Seeker)
+		if !ok {
+			return newIOError(errors.New("reader is not seekable"))
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return newIOError(err)
+		}
+		this.buf = this.buf[:0]
+		this.pos = 0
+		this.offset = offset
+		this.err = nil
+	}
+	this.state.clear()
+	return nil
+}
+
+// Read decodes the next record, or skips a damaged region and returns a
+// *CorruptionError.
+func (this *Reader) Read(record *Record) error {
+	if record == nil {
+		panic(readingErrPrefix + ": record is nil")
+	}
+
+	this.resume()
+	start := this.Offset()
+	err := this.read(record)
+	switch err.(type) {
+	case nil, *IOError, *TruncatedError:
+		return err
+	}
+	if err == EOF {
+		return err
+	}
+
+	this.pos++
+	this.resync()
+	return newCorruptionError(start, this.Offset()-start, err)
+}
+
+// TryRead skips to the next position from which a record can be decoded and
+// reads the record there.
+func (this *Reader) TryRead(record *Record) error {
+	if record == nil {
+		panic(readingErrPrefix + ": record is nil")
+	}
+
+	this.resume()
+	// A chained record can only be found where the previous one ends.
+	if this.pos == len(this.buf) && this.err == nil {
+		this.fill()
+	}
+	if this.state.started && this.pos < len(this.buf) && this.buf[this.pos] == chainMark {
+		return this.Read(record)
+	}
+
+	start := this.Offset()
+	if err := this.resync(); err != nil {
+		if err != io.EOF {
+			return newIOError(err)
+		} else if this.Offset() == start {
+			return EOF
+		}
+		return newIOError(io.EOF)
+	}
+	return this.Read(record)
+}
+
+// resume makes the next fill read again after the end of the input was
+// reached, since more data may have been appended.
+func (this *Reader) resume() {
+	if this.err == io.EOF && this.reader != nil {
+		this.err = nil
+	}
+}
+
+func (this *Reader) read(record *Record) error {
+	for {
+		var n int
+		var err error
+		data := this.buf[this.pos:]
+		header := isHeaderFrame(data)
+		if header {
+			n, err = decodeHeader(&this.header, data)
+		} else {
+			n, err = decodeRecord(record, data, &this.state)
+		}
+		switch {
+		case err == nil && header:
+			this.pos += n
+			this.hasHeader = true
+		case err == nil:
+			this.pos += n
+			return nil
+		case err == errShortData && this.err == nil:
+			this.fill()
+		case err == errShortData && this.err != io.EOF:
+			this.pos = len(this.buf)
+			return newIOError(this.err)
+		case err == errShortData && this.pos == len(this.buf):
+			return EOF
+		case err == errShortData && frameFollows(data[1:]):
+			// The length is damaged rather than the tail unwritten.
+			return newFormatError("record runs past the next record")
+		case err == errShortData:
+			// Stay at the record, so that it is retried by the next read.
+			return newTruncatedError(this.Offset())
+		default:
+			return err
+		}
+	}
+}
+
+// resync moves to the next magic from which a record can be decoded, or to
+// the end of the input.
+func (this *Reader) resync() error {
+	for {
+		data := this.buf[this.pos:]
+		i := bytes.Index(data, binaryMagic)
+		if i < 0 {
+			if len(data) > maxPartialMagicLen {
+				this.pos = len(this.buf) - maxPartialMagicLen
+			}
+			if this.err != nil {
+				this.pos = len(this.buf) - partialMagicLen(this.buf[this.pos:])
+				return this.err
+			}
+			this.fill()
+			continue
+		}
+
+		this.pos += i
+		err := checkFrame(this.buf[this.pos:], this.state.started)
+		switch {
+		case err == errShortData && this.err == nil:
+			this.fill()
+		case err == nil || err == errShortData:
+			return nil
+		default:
+			this.pos++
+		}
+	}
+}
+
+// frameFollows tells whether a record can be decoded somewhere in data.
+func frameFollows(data []byte) bool {
+	for {
+		i := bytes.Index(data, binaryMagic)
+		if i < 0 {
+			return false
+		}
+		if checkFrame(data[i:], true) == nil {
+			return true
+		}
+		data = data[i+1:]
+	}
+}
+
+// partialMagicLen returns the length of the longest suffix of data that may be
+// the beginning of a magic.
+func partialMagicLen(data []byte) int {
+	for n := min(len(data), maxPartialMagicLen); n > 0; n-- {
+		if bytes.HasPrefix(binaryMagic, data[len(data)-n:]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// fill compacts the buffer and reads more input into it.
+func (this *Reader) fill() {
+	if this.pos > 0 {
+		this.offset += int64(this.pos)
+		n := copy(this.buf, this.buf[this.pos:])
+		this.buf = this.buf[:n]
+		this.pos = 0
+	}
+	if len(this.buf) == cap(this.buf) {
+		buf := make([]byte, len(this.buf), 2*cap(this.buf))
+		copy(buf, this.buf)
+		this.buf = buf
+	}
+
+	for i := 0; i < maxEmptyReads; i++ {
+		n, err := this.reader.Read(this.buf[len(this.buf):cap(this.buf)])
+		this.buf = this.buf[:len(this.buf)+n]
+		if err != nil {
+			this.err = err
+			return
+		}
+		if n > 0 {
+			return
+		}
+	}
+	this.err = io.ErrNoProgress
+}
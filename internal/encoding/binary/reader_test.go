@@ -0,0 +1,267 @@
+package binary
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// testRecord returns the i-th record of a test stream, with contexts of
+// assorted kinds.
+func testRecord(i int) *Record {
+	record := &Record{
+		Time:  time.Unix(1700000000, int64(i)),
+		Level: iface.Info,
+		Pkg:   "github.com/a/b",
+		File:  "b.go",
+		Line:  10 + i,
+		Msg:   fmt.Sprintf("msg %d", i),
+		Seq:   uint64(i + 1),
+	}
+	record.AddContext("int", int64(i))
+	record.AddContext("str", "value")
+	record.AddContext("bytes", []byte{1, 2, 3})
+	record.AddContext("dur", time.Duration(i)*time.Second)
+	record.AddContext("obj", ObjectValue{{Key: "inner", Kind: Bool, bits: 1}})
+	return record
+}
+
+// testStream encodes n test records in version 2 format.
+func testStream(n int) []byte {
+	var data []byte
+	for i := 0; i < n; i++ {
+		data = AppendRecord(data, testRecord(i))
+	}
+	return data
+}
+
+func checkRecord(t *testing.T, record *Record, i int) {
+	t.Helper()
+
+	want := testRecord(i)
+	if record.Msg != want.Msg || record.Line != want.Line || record.Seq != want.Seq ||
+		!record.Time.Equal(want.Time) || record.Pkg != want.Pkg {
+		t.Fatalf("record %d = %+v", i, record)
+	}
+	if v := record.Context("int").Int(); v != int64(i) {
+		t.Errorf("record %d: int = %d", i, v)
+	}
+	if v := record.Context("str").Str(); v != "value" {
+		t.Errorf("record %d: str = %q", i, v)
+	}
+	if v := record.Context("bytes").Bytes(); !bytes.Equal(v, []byte{1, 2, 3}) {
+		t.Errorf("record %d: bytes = %v", i, v)
+	}
+	if v := record.Context("dur").Duration(); v != time.Duration(i)*time.Second {
+		t.Errorf("record %d: dur = %v", i, v)
+	}
+	if elems := record.Context("obj").Elems(); len(elems) != 1 || !elems[0].Bool() {
+		t.Errorf("record %d: obj = %+v", i, elems)
+	}
+}
+
+func TestReader(t *testing.T) {
+	const n = 100
+	data := testStream(n)
+	readers := map[string]*Reader{
+		"bytes":    NewBytesReader(data),
+		"one byte": NewReader(iotest.OneByteReader(bytes.NewReader(data))),
+	}
+	for name, reader := range readers {
+		var record Record
+		for i := 0; i < n; i++ {
+			if err := reader.Read(&record); err != nil {
+				t.Fatalf("%s: record %d: %v", name, i, err)
+			}
+			checkRecord(t, &record, i)
+		}
+		if err := reader.Read(&record); err != EOF {
+			t.Errorf("%s: read at the end = %v, want EOF", name, err)
+		}
+		if offset := reader.Offset(); offset != int64(len(data)) {
+			t.Errorf("%s: offset at the end = %d, want %d", name, offset, len(data))
+		}
+	}
+}
+
+func TestReaderZeroCopy(t *testing.T) {
+	data := testStream(1)
+	var record Record
+	if err := NewBytesReader(data).Read(&record); err != nil {
+		t.Fatal(err)
+	}
+	data[bytes.Index(data, []byte("value"))] = 'V'
+	if v := record.Context("str").Str(); v != "Value" {
+		t.Errorf("str = %q, want it to refer to the input", v)
+	}
+}
+
+func TestReaderAllocs(t *testing.T) {
+	data := testStream(10)
+	reader := NewBytesReader(data)
+	var record Record
+	readAll := func() {
+		if err := reader.SeekTo(0); err != nil {
+			t.Fatal(err)
+		}
+		for {
+			err := reader.Read(&record)
+			if err == EOF {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	readAll() // grow the contexts of the record
+	if allocs := testing.AllocsPerRun(100, readAll); allocs != 0 {
+		t.Errorf("%v allocs per stream, want 0", allocs)
+	}
+}
+
+func TestReaderFollow(t *testing.T) {
+	data := testStream(2)
+	var buf bytes.Buffer
+	reader := NewReader(&buf)
+	var record Record
+
+	first := len(AppendRecord(nil, testRecord(0)))
+	buf.Write(data[:first+5])
+	if err := reader.Read(&record); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &record, 0)
+	var truncated *TruncatedError
+	if err := reader.Read(&record); !errors.As(err, &truncated) || truncated.Offset != int64(first) {
+		t.Fatalf("read of a partial record = %v, want a TruncatedError at %d", err, first)
+	}
+
+	buf.Write(data[first+5:])
+	if err := reader.Read(&record); err != nil {
+		t.Fatal(err)
+	}
+	checkRecord(t, &record, 1)
+	if err := reader.Read(&record); err != EOF {
+		t.Errorf("read at the end = %v, want EOF", err)
+	}
+}
+
+func TestReaderIOError(t *testing.T) {
+	failure := errors.New("failure")
+	data := testStream(1)
+	reader := NewReader(io.MultiReader(bytes.NewReader(data[:10]), iotest.ErrReader(failure)))
+	var record Record
+	var ioErr *IOError
+	if err := reader.Read(&record); !errors.As(err, &ioErr) || ioErr.Err != failure {
+		t.Errorf("read = %v, want an IOError", err)
+	}
+}
+
+func TestContextAccessorMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Str on an Int64 context did not panic")
+		}
+	}()
+	context, _ := NewContext("key", int64(1))
+	context.Str()
+}
+
+// TestReaderDamage checks that a record cut off by the end of the input is
+// told apart from a damaged one, after which reading goes on.
+func TestReaderDamage(t *testing.T) {
+	const n = DictResetInterval + 2
+	tests := []struct {
+		name      string
+		damage    func(data []byte, offsets []int) []byte
+		read      []int
+		corrupted bool
+		truncated bool
+	}{
+		{
+			name: "tail",
+			damage: func(data []byte, offsets []int) []byte {
+				return data[:len(data)-3]
+			},
+			read:      recordRange(0, n-1),
+			truncated: true,
+		},
+		{
+			name: "length",
+			damage: func(data []byte, offsets []int) []byte {
+				putUint32(data[offsets[0]+sizeOfHeader:], 1<<20)
+				return data
+			},
+			read:      recordRange(DictResetInterval, n),
+			corrupted: true,
+		},
+		{
+			name: "payload",
+			damage: func(data []byte, offsets []int) []byte {
+				data[offsets[2]-1] ^= 0xff
+				return data
+			},
+			read:      append(recordRange(0, 1), recordRange(DictResetInterval, n)...),
+			corrupted: true,
+		},
+		{
+			// Without a record after it, a damaged length cannot be told
+			// from a tail yet to be written.
+			name: "length of the last block",
+			damage: func(data []byte, offsets []int) []byte {
+				putUint32(data[offsets[DictResetInterval]+sizeOfHeader:], 1<<20)
+				return data
+			},
+			read:      recordRange(0, DictResetInterval),
+			truncated: true,
+		},
+	}
+
+	for _, test := range tests {
+		data, offsets := testCompactStream(n)
+		reader := NewBytesReader(test.damage(data, offsets))
+		var record Record
+		var read []int
+		var corrupted, truncated bool
+		for !truncated {
+			err := reader.Read(&record)
+			if err == EOF {
+				break
+			}
+			var corruption *CorruptionError
+			var truncation *TruncatedError
+			switch {
+			case errors.As(err, &corruption):
+				corrupted = true
+			case errors.As(err, &truncation):
+				truncated = true
+			case err != nil:
+				t.Fatalf("%s: %v", test.name, err)
+			default:
+				read = append(read, int(record.Seq-1))
+			}
+		}
+		if corrupted != test.corrupted || truncated != test.truncated {
+			t.Errorf("%s: corrupted %v, truncated %v, want %v, %v",
+				test.name, corrupted, truncated, test.corrupted, test.truncated)
+		}
+		if fmt.Sprint(read) != fmt.Sprint(test.read) {
+			t.Errorf("%s: read %v, want %v", test.name, read, test.read)
+		}
+	}
+}
+
+func recordRange(from, to int) []int {
+	var seqs []int
+	for i := from; i < to; i++ {
+		seqs = append(seqs, i)
+	}
+	return seqs
+}
@@ -9,16 +9,105 @@ import (
 )
 
 type Record struct {
-	Time     time.Time
-	Pkg      string
-	File     string
-	Line     int
-	Msg      string
-	Contexts []Context
-	Mark     bool
-	Level    iface.Level
+	Time      time.Time
+	Pkg       string
+	File      string
+	Line      int
+	Func      string
+	Msg       string
+	Template  string
+	Contexts  []Context
+	Stack     []Frame
+	Mark      bool
+	Level     iface.Level
+	Verbosity int
+	Seq       uint64
+
+	format string
+	reader sliceReader
+}
+
+type Frame struct {
+	Func string
+	File string
+	Line int
+}
+
+func (this *Record) AddContext(key string, value interface{}) error {
+	context, err := NewContext(key, value)
+	if err != nil {
+		return err
+	}
+	this.Contexts = append(this.Contexts, context)
+	return nil
+}
+
+// Context returns the first context with the given key, or nil if there is
+// none.
+func (this *Record) Context(key string) *Context {
+	for i := range this.Contexts {
+		if this.Contexts[i].Key == key {
+			return &this.Contexts[i]
+		}
+	}
+	return nil
 }
 
+func AppendRecord(dst []byte, record *Record) []byte {
+	if record == nil {
+		panic("glog: append binary record: record is nil")
+	}
+
+	dst = AppendBinaryMeta(dst)
+	dst = AppendLevel(dst, record.Level)
+	if record.Verbosity != 0 {
+		dst = AppendVerbosity(dst, record.Verbosity)
+	}
+	dst = AppendPkg(dst, record.Pkg)
+	if record.File != "" {
+		dst = AppendFile(dst, record.File)
+	}
+	if record.Line != 0 {
+		dst = AppendLine(dst, record.Line)
+	}
+	if record.Func != "" {
+		dst = AppendFunc(dst, record.Func)
+	}
+	if record.Mark {
+		dst = AppendMark(dst)
+	}
+	for i := range record.Contexts {
+		dst = AppendContext(dst, &record.Contexts[i])
+	}
+	if len(record.Stack) != 0 {
+		dst = AppendStack(dst, record.Stack)
+	}
+	if record.Template != "" {
+		dst = AppendTemplate(dst, record.Template)
+	}
+	dst = AppendMsg(dst, record.Msg)
+	dst = AppendTime(dst, record.Time)
+	if record.Seq != 0 {
+		dst = AppendSeq(dst, record.Seq)
+	}
+	dst = AppendEnd(dst)
+	return dst
+}
+
+// DecodeRecord decodes the record at the beginning of data, referring to data
+// without copying, and returns its size. Use Reader for streams.
+func DecodeRecord(record *Record, data []byte) (int, error) {
+	if record == nil {
+		panic(readingErrPrefix + ": record is nil")
+	}
+	if len(data) == 0 {
+		return 0, EOF
+	}
+
+	return decodeRecord(record, data, nil)
+}
+
+// ReadRecord reads exactly one record from reader.
 func ReadRecord(record *Record, reader io.Reader) error {
 	if record == nil {
 		panic(readingErrPrefix + ": record is nil")
@@ -54,14 +143,65 @@ func TryReadRecord(record *Record, reader io.Reader) error {
 	return readRecord(record, reader)
 }
 
+// readRecord reads the rest of a record whose magic has been consumed.
 func readRecord(record *Record, reader io.Reader) error {
+	data := make([]byte, sizeOfMagic, 256)
+	copy(data, binaryMagic)
+	for {
+		_, err := decodeRecord(record, data, nil)
+		if err != errShortData {
+			return err
+		}
+
+		size := len(data)
+		data = append(data, make([]byte, record.reader.need)...)
+		if err := read(data[size:], reader); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeRecord decodes a record from the beginning of data against state,
+// which may be nil outside of a stream, and returns its size.
+func decodeRecord(record *Record, data []byte, state *streamState) (int, error) {
+	record.reset(data, state)
+	reader := &record.reader
+
+	if len(data) != 0 && data[0] == chainMark {
+		reader.pos++
+		err := readChainedRecord(record, reader)
+		return reader.pos, err
+	}
+
+	magic, err := reader.next(sizeOfMagic)
+	if err != nil {
+		return reader.pos, err
+	}
+	if !bytes.Equal(magic, binaryMagic) {
+		return reader.pos, newMagicError(append([]byte(nil), magic...))
+	}
+
 	version, err := readVersion(reader)
 	if err != nil {
-		return err
+		return reader.pos, err
 	}
-	if version != binaryVersion {
-		return newVersionError(version)
+	switch version {
+	case legacyVersion, binaryVersion:
+		err = readFields(record, reader)
+	case compactVersion:
+		err = readFramedRecord(record, reader)
+	case headerMark:
+		err = newFormatError("unexpected file header")
+	default:
+		err = newVersionError(version)
 	}
+	return reader.pos, err
+}
 
-	return readFields(record, reader)
+func (this *Record) reset(data []byte, state *streamState) {
+	*this = Record{
+		Contexts: this.Contexts[:0],
+		Stack:    this.Stack[:0],
+		reader:   sliceReader{data: data, state: state},
+	}
 }
@@ -0,0 +1,232 @@
+package binary
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Context is a keyed value of a record, read through the accessor of its Kind.
+type Context struct {
+	Key    string
+	Format string
+	Kind   ValueKind
+
+	bits  uint64
+	imag  uint64
+	str   string
+	bytes []byte
+	err   *ErrorValue
+	elems []Context
+}
+
+func NewContext(key string, value interface{}) (Context, error) {
+	context := Context{Key: key}
+	switch v := value.(type) {
+	case bool:
+		context.Kind = Bool
+		context.setBool(v)
+	case int8:
+		context.Kind, context.bits = Int8, uint64(v)
+	case int16:
+		context.Kind, context.bits = Int16, uint64(v)
+	case int32:
+		context.Kind, context.bits = Int32, uint64(v)
+	case int64:
+		context.Kind, context.bits = Int64, uint64(v)
+	case int:
+		context.Kind, context.bits = Int64, uint64(v)
+	case uint8:
+		context.Kind, context.bits = Uint8, uint64(v)
+	case uint16:
+		context.Kind, context.bits = Uint16, uint64(v)
+	case uint32:
+		context.Kind, context.bits = Uint32, uint64(v)
+	case uint64:
+		context.Kind, context.bits = Uint64, v
+	case uint:
+		context.Kind, context.bits = Uint64, uint64(v)
+	case uintptr:
+		context.Kind, context.bits = Uintptr, uint64(v)
+	case float32:
+		context.Kind, context.bits = Float32, math.Float64bits(float64(v))
+	case float64:
+		context.Kind, context.bits = Float64, math.Float64bits(v)
+	case complex64:
+		context.Kind = Complex64
+		context.setComplex(complex128(v))
+	case complex128:
+		context.Kind = Complex128
+		context.setComplex(v)
+	case string:
+		context.Kind, context.str = String, v
+	case []byte:
+		context.Kind, context.bytes = Bytes, v
+	case time.Time:
+		context.Kind, context.bits = Time, uint64(v.UnixNano())
+	case time.Duration:
+		context.Kind, context.bits = Duration, uint64(v)
+	case ErrorValue:
+		context.Kind, context.err = Error, &v
+	case error:
+		errValue := NewErrorValue(v)
+		context.Kind, context.err = Error, &errValue
+	case ArrayValue:
+		context.Kind, context.elems = Array, v
+	case ObjectValue:
+		context.Kind, context.elems = Object, v
+	default:
+		return Context{}, fmt.Errorf("glog: new context: unsupported value type %T", value)
+	}
+	return context, nil
+}
+
+func (this *Context) Bool() bool {
+	this.mustBe("Bool", Bool)
+	return this.bits != 0
+}
+
+// Int returns the value of a signed integer context: Int8, Int16, Int32,
+// Int64 or Rune.
+func (this *Context) Int() int64 {
+	this.mustBe("Int", Int8, Int16, Int32, Int64, Rune)
+	return int64(this.bits)
+}
+
+// Uint returns the value of an unsigned integer context: Byte, Uint8, Uint16,
+// Uint32, Uint64 or Uintptr.
+func (this *Context) Uint() uint64 {
+	this.mustBe("Uint", Byte, Uint8, Uint16, Uint32, Uint64, Uintptr)
+	return this.bits
+}
+
+func (this *Context) Float() float64 {
+	this.mustBe("Float", Float32, Float64)
+	return math.Float64frombits(this.bits)
+}
+
+func (this *Context) Complex() complex128 {
+	this.mustBe("Complex", Complex64, Complex128)
+	return complex(math.Float64frombits(this.bits), math.Float64frombits(this.imag))
+}
+
+func (this *Context) Str() string {
+	this.mustBe("Str", String)
+	return this.str
+}
+
+func (this *Context) Bytes() []byte {
+	this.mustBe("Bytes", Bytes)
+	return this.bytes
+}
+
+func (this *Context) Time() time.Time {
+	this.mustBe("Time", Time)
+	return time.Unix(0, int64(this.bits))
+}
+
+func (this *Context) Duration() time.Duration {
+	this.mustBe("Duration", Duration)
+	return time.Duration(this.bits)
+}
+
+func (this *Context) Err() ErrorValue {
+	this.mustBe("Err", Error)
+	return this.errValue()
+}
+
+// Elems returns the elements of an Array or the fields of an Object context.
+func (this *Context) Elems() []Context {
+	this.mustBe("Elems", Array, Object)
+	return this.elems
+}
+
+func (this *Context) Value() interface{} {
+	switch this.Kind {
+	case Bool:
+		return this.bits != 0
+	case Byte:
+		return byte(this.bits)
+	case Rune:
+		return rune(this.bits)
+	case Int8:
+		return int8(this.bits)
+	case Int16:
+		return int16(this.bits)
+	case Int32:
+		return int32(this.bits)
+	case Int64:
+		return int64(this.bits)
+	case Uint8:
+		return uint8(this.bits)
+	case Uint16:
+		return uint16(this.bits)
+	case Uint32:
+		return uint32(this.bits)
+	case Uint64:
+		return this.bits
+	case Uintptr:
+		return uintptr(this.bits)
+	case Float32:
+		return float32(this.Float())
+	case Float64:
+		return this.Float()
+	case Complex64:
+		return complex64(this.Complex())
+	case Complex128:
+		return this.Complex()
+	case String:
+		return this.str
+	case Bytes:
+		return this.bytes
+	case Time:
+		return this.Time()
+	case Duration:
+		return this.Duration()
+	case Error:
+		return this.errValue()
+	case Array:
+		return ArrayValue(this.elems)
+	case Object:
+		return ObjectValue(this.elems)
+	default:
+		panic(fmt.Sprintf("glog: illegal value kind %d", this.Kind))
+	}
+}
+
+func (this *Context) setBool(b bool) {
+	if b {
+		this.bits = 1
+	} else {
+		this.bits = 0
+	}
+}
+
+func (this *Context) setComplex(c complex128) {
+	this.bits = math.Float64bits(real(c))
+	this.imag = math.Float64bits(imag(c))
+}
+
+func (this *Context) errValue() ErrorValue {
+	if this.err == nil {
+		return ErrorValue{}
+	}
+	return *this.err
+}
+
+// reset prepares the context for decoding a new value, keeping the memory of
+// its nested values and error value for reuse.
+func (this *Context) reset(key string, kind ValueKind) {
+	this.Key, this.Format, this.Kind = key, "", kind
+	this.bits, this.imag = 0, 0
+	this.str, this.bytes = "", nil
+}
+
+func (this *Context) mustBe(method string, kinds ...ValueKind) {
+	for _, kind := range kinds {
+		if this.Kind == kind {
+			return
+		}
+	}
+	panic(fmt.Sprintf("glog: context '%s': %s called on value kind %d", this.Key, method, this.Kind))
+}
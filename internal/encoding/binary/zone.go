@@ -0,0 +1,31 @@
+package binary
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const maxZoneOffset = 24 * 60 * 60
+
+// Zones are cached by offset, so that decoding does not allocate.
+var (
+	zones    = map[int]*time.Location{0: time.UTC}
+	zoneLock sync.Mutex
+)
+
+func fixedZone(offset int) (*time.Location, error) {
+	if offset < -maxZoneOffset || offset > maxZoneOffset {
+		return nil, newFormatError(fmt.Sprintf("zone offset out of range: %d", offset))
+	}
+
+	zoneLock.Lock()
+	defer zoneLock.Unlock()
+
+	location := zones[offset]
+	if location == nil {
+		location = time.FixedZone("", offset)
+		zones[offset] = location
+	}
+	return location, nil
+}
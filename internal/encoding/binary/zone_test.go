@@ -0,0 +1,62 @@
+package binary
+
+import (
+	"testing"
+	"time"
+)
+
+func TestZoneAndSeq(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	zones := []int{3600, 3600, -5 * 3600, 0, 3600}
+	seqs := []uint64{7, 8, 0, 1000, 9}
+
+	var records []*Record
+	for i := range zones {
+		record := testRecord(i)
+		record.Time = base.Add(time.Duration(i) * time.Millisecond).In(time.FixedZone("", zones[i]))
+		record.Seq = seqs[i]
+		records = append(records, record)
+	}
+
+	var legacy, compact []byte
+	var encoder Encoder
+	for _, record := range records {
+		legacy = AppendRecord(legacy, record)
+		compact = encoder.AppendRecord(compact, record)
+	}
+
+	for name, data := range map[string][]byte{"version 2": legacy, "version 1": compact} {
+		reader := NewBytesReader(data)
+		var record Record
+		for i, want := range records {
+			if err := reader.Read(&record); err != nil {
+				t.Fatalf("%s: record %d: %v", name, i, err)
+			}
+			if _, offset := record.Time.Zone(); offset != zones[i] {
+				t.Errorf("%s: record %d: zone offset %d, want %d", name, i, offset, zones[i])
+			}
+			if !record.Time.Equal(want.Time) || record.Time.Format(time.RFC3339Nano) != want.Time.Format(time.RFC3339Nano) {
+				t.Errorf("%s: record %d: time %v, want %v", name, i, record.Time, want.Time)
+			}
+			if record.Seq != seqs[i] {
+				t.Errorf("%s: record %d: seq %d, want %d", name, i, record.Seq, seqs[i])
+			}
+		}
+	}
+}
+
+func TestFixedZone(t *testing.T) {
+	first, err := fixedZone(3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := fixedZone(3600); second != first {
+		t.Error("zone not cached")
+	}
+	if utc, _ := fixedZone(0); utc != time.UTC {
+		t.Error("offset 0 is not UTC")
+	}
+	if _, err := fixedZone(maxZoneOffset + 1); err == nil {
+		t.Error("offset out of range accepted")
+	}
+}
@@ -0,0 +1,44 @@
+package text
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime/quotedprintable"
+	"strings"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func FormatBytes(b []byte, config *iface.TextConfig) string {
+	var rest int
+	if config.BytesLimit > 0 && len(b) > config.BytesLimit {
+		rest = len(b) - config.BytesLimit
+		b = b[:config.BytesLimit]
+	}
+
+	var str string
+	switch config.BytesFormat {
+	case iface.BytesHex:
+		str = strings.TrimSuffix(hex.Dump(b), "\n")
+	case iface.BytesBase64:
+		str = base64.StdEncoding.EncodeToString(b)
+	case iface.BytesQuoted:
+		str = quotedPrintable(b)
+	default:
+		panic(fmt.Sprintf("glog: illegal bytes format '%d'", config.BytesFormat))
+	}
+
+	if rest > 0 {
+		str += fmt.Sprintf("...(%d more bytes)", rest)
+	}
+	return str
+}
+
+func quotedPrintable(b []byte) string {
+	var builder strings.Builder
+	writer := quotedprintable.NewWriter(&builder)
+	writer.Write(b)
+	writer.Close()
+	return strings.ReplaceAll(builder.String(), "\r\n", "\n")
+}
@@ -0,0 +1,35 @@
+package text
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestFormatBytes(t *testing.T) {
+	data := []byte("ab\x00\xff")
+	long := []byte(strings.Repeat("a", 80))
+	tests := []struct {
+		data   []byte
+		format iface.BytesFormat
+		limit  int
+		want   string
+	}{
+		{data, iface.BytesHex, 0, "00000000  61 62 00 ff                                       |ab..|"},
+		{data, iface.BytesBase64, 0, "YWIA/w=="},
+		{data, iface.BytesQuoted, 0, "ab=00=FF"},
+		{data, iface.BytesHex, 2, "00000000  61 62                                             |ab|...(2 more bytes)"},
+		{data, iface.BytesQuoted, 4, "ab=00=FF"},
+		{data, iface.BytesBase64, -1, "YWIA/w=="},
+		{long[:17], iface.BytesHex, 0, "00000000  61 61 61 61 61 61 61 61  61 61 61 61 61 61 61 61  |aaaaaaaaaaaaaaaa|\n" +
+			"00000010  61                                                |a|"},
+		{long, iface.BytesQuoted, 0, strings.Repeat("a", 75) + "=\n" + strings.Repeat("a", 5)},
+	}
+	for _, test := range tests {
+		config := iface.TextConfig{BytesFormat: test.format, BytesLimit: test.limit}
+		if got := FormatBytes(test.data, &config); got != test.want {
+			t.Errorf("format %d, limit %d: got %q, want %q", test.format, test.limit, got, test.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gratonos/glog/internal/encoding/binary"
@@ -11,20 +12,14 @@ import (
 )
 
 const (
-	timeLayout = "2006-01-02 15:04:05.000000"
-	separator  = " "
-	logMark    = "@@@@@@@@"
+	timeLayout  = "2006-01-02 15:04:05.000000"
+	separator   = " "
+	logMark     = "@@@@@@@@"
+	headerMark  = "########"
+	stackIndent = "    "
+	levelWidth  = 5
 )
 
-var levelDesc = [...]string{
-	iface.Trace: "TRACE",
-	iface.Debug: "DEBUG",
-	iface.Info:  "INFO ",
-	iface.Warn:  "WARN ",
-	iface.Error: "ERROR",
-	iface.Fatal: "FATAL",
-}
-
 var defaultFormats = [...]string{
 	binary.Bool:       "%t",
 	binary.Byte:       "%#02x",
@@ -45,21 +40,50 @@ var defaultFormats = [...]string{
 	binary.String:     "%s",
 	binary.Time:       timeLayout,
 	binary.Duration:   "%s",
+	binary.Error:      "%s",
+	binary.Array:      "%s",
+	binary.Object:     "%s",
+	binary.Bytes:      "%s",
 }
 
-func FormatRecord(record *binary.Record, coloring bool) []byte {
+func FormatRecord(record *binary.Record, config iface.TextConfig) []byte {
 	buf := new(bytes.Buffer)
-	dyer := newTextDyer(buf, record.Level, coloring)
+	dyer := newTextDyer(buf, record.Level, config.Coloring)
 
-	formatTime(dyer, record.Time)
+	formatTime(dyer, InZone(record.Time, &config))
 	formatLevel(dyer, record.Level)
+	formatVerbosity(dyer, record.Verbosity)
 	formatMark(dyer, record.Mark)
 	formatPkg(dyer, record.Pkg)
 	formatFile(dyer, record.File)
 	formatLine(dyer, record.Line)
+	formatFunc(dyer, record.Func)
 	formatMsg(dyer, record.Msg)
-	formatContexts(dyer, record.Contexts)
+	formatContexts(dyer, record.Contexts, &config)
+	formatStack(dyer, record.Stack)
+	formatErrorStacks(dyer, record.Contexts)
+
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
 
+// FormatHeader formats a file header as a line of its own.
+func FormatHeader(header *binary.FileHeader, config iface.TextConfig) []byte {
+	buf := new(bytes.Buffer)
+	if config.Coloring {
+		buf.WriteString(Cyan)
+	}
+	tm := header.Time.In(header.Location())
+	fmt.Fprintf(buf, "%s %s logger '%s' on %s by %s (pid %d, %s), format v%d, zone %s",
+		headerMark, InZone(tm, &config).Format(timeLayout), header.Logger, header.Hostname,
+		header.Executable, header.Pid, header.GoVersion, header.Version, tm.Format("MST -07:00"))
+	if header.Previous != "" {
+		fmt.Fprintf(buf, ", previous %s", header.Previous)
+	}
+	buf.WriteString(separator + headerMark)
+	if config.Coloring {
+		buf.WriteString(Reset)
+	}
 	buf.WriteByte('\n')
 	return buf.Bytes()
 }
@@ -72,8 +96,19 @@ func formatLevel(dyer *textDyer, level iface.Level) {
 	if !level.LegalForLog() {
 		panic(fmt.Sprintf("glog: illegal log level: %d", level))
 	}
+	desc := level.String()
+	if len(desc) < levelWidth {
+		desc += strings.Repeat(" ", levelWidth-len(desc))
+	}
 	dyer.Write(separator)
-	dyer.DyeContent(levelDesc[level])
+	dyer.DyeContent(desc)
+}
+
+func formatVerbosity(dyer *textDyer, verbosity int) {
+	if verbosity != 0 {
+		dyer.Write(separator)
+		dyer.DyeContent("V" + strconv.Itoa(verbosity))
+	}
 }
 
 func formatMark(dyer *textDyer, mark bool) {
@@ -102,6 +137,14 @@ func formatLine(dyer *textDyer, line int) {
 	}
 }
 
+func formatFunc(dyer *textDyer, fn string) {
+	if fn != "" {
+		dyer.Write(separator)
+		dyer.DyeContent(fn)
+		dyer.DyeSymbol("()")
+	}
+}
+
 func formatMsg(dyer *textDyer, msg string) {
 	dyer.Write(separator)
 	dyer.DyeSymbol("<")
@@ -109,9 +152,9 @@ func formatMsg(dyer *textDyer, msg string) {
 	dyer.DyeSymbol(">")
 }
 
-func formatContexts(dyer *textDyer, contexts []binary.Context) {
+func formatContexts(dyer *textDyer, contexts []binary.Context, config *iface.TextConfig) {
 	for _, context := range contexts {
-		formatContext(dyer, context.Key, formatValue(&context))
+		formatContext(dyer, context.Key, formatValue(&context, config))
 	}
 }
 
@@ -125,7 +168,33 @@ func formatContext(dyer *textDyer, key, value string) {
 	dyer.DyeSymbol(")")
 }
 
-func formatValue(context *binary.Context) string {
+func formatStack(dyer *textDyer, stack []binary.Frame) {
+	for _, frame := range stack {
+		dyer.Write("\n" + stackIndent)
+		dyer.DyeContent(frame.Func)
+		dyer.Write("\n" + stackIndent + stackIndent)
+		dyer.DyeContent(frame.File)
+		dyer.DyeSymbol(":")
+		dyer.DyeContent(strconv.Itoa(frame.Line))
+	}
+}
+
+func formatErrorStacks(dyer *textDyer, contexts []binary.Context) {
+	for _, context := range contexts {
+		if context.Kind != binary.Error {
+			continue
+		}
+		origin := context.Err().Origin()
+		if len(origin.Stack) != 0 {
+			dyer.Write("\n" + stackIndent)
+			dyer.DyeKey(context.Key)
+			dyer.DyeSymbol(":")
+			formatStack(dyer, origin.Stack)
+		}
+	}
+}
+
+func formatValue(context *binary.Context, config *iface.TextConfig) string {
 	kind := context.Kind
 	if !kind.Legal() {
 		panic(fmt.Sprintf("glog: illegal value kind %d", kind))
@@ -137,12 +206,56 @@ func formatValue(context *binary.Context) string {
 	}
 
 	var value string
-	if kind == binary.Time {
-		tm := context.Value.(time.Time)
-		value = tm.Format(format)
-	} else {
-		value = fmt.Sprintf(format, context.Value)
+	switch kind {
+	case binary.Time:
+		value = InZone(context.Time(), config).Format(format)
+	case binary.Error:
+		value = fmt.Sprintf(format, errorText(context.Err()))
+	case binary.Array:
+		value = fmt.Sprintf(format, arrayText(context.Elems(), config))
+	case binary.Object:
+		value = fmt.Sprintf(format, objectText(context.Elems(), config))
+	case binary.Bytes:
+		value = fmt.Sprintf(format, FormatBytes(context.Bytes(), config))
+	default:
+		value = fmt.Sprintf(format, context.Value())
 	}
 
 	return value
 }
+
+func errorText(value binary.ErrorValue) string {
+	text := value.Msg
+	if value.Type != "" {
+		text += " [" + value.Type + "]"
+	}
+
+	switch len(value.Causes) {
+	case 0:
+	case 1:
+		text += " <- " + errorText(value.Causes[0])
+	default:
+		causes := make([]string, 0, len(value.Causes))
+		for _, cause := range value.Causes {
+			causes = append(causes, errorText(cause))
+		}
+		text += " <- {" + strings.Join(causes, "; ") + "}"
+	}
+	return text
+}
+
+func arrayText(array []binary.Context, config *iface.TextConfig) string {
+	elems := make([]string, 0, len(array))
+	for i := range array {
+		elems = append(elems, formatValue(&array[i], config))
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+func objectText(object []binary.Context, config *iface.TextConfig) string {
+	elems := make([]string, 0, len(object))
+	for i := range object {
+		elems = append(elems, object[i].Key+": "+formatValue(&object[i], config))
+	}
+	return "{" + strings.Join(elems, ", ") + "}"
+}
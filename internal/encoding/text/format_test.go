@@ -0,0 +1,84 @@
+package text
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestFormatStack(t *testing.T) {
+	record := binary.Record{
+		Time:  time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Level: iface.Error,
+		Pkg:   "main",
+		Msg:   "failed",
+		Stack: []binary.Frame{
+			{Func: "main.run", File: "/src/main.go", Line: 20},
+			{Func: "main.main", File: "/src/main.go", Line: 12},
+		},
+	}
+
+	text := string(FormatRecord(&record, iface.TextConfig{}))
+	want := "2024-05-06 07:08:09.000000 ERROR main <failed>\n" +
+		"    main.run\n" +
+		"        /src/main.go:20\n" +
+		"    main.main\n" +
+		"        /src/main.go:12\n"
+	if text != want {
+		t.Errorf("got:\n%s\nwant:\n%s", text, want)
+	}
+}
+
+func TestFormatNested(t *testing.T) {
+	context := func(key string, value interface{}) binary.Context {
+		context, err := binary.NewContext(key, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return context
+	}
+	record := binary.Record{
+		Time:  time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Level: iface.Info,
+		Pkg:   "main",
+		Msg:   "hello",
+		Contexts: []binary.Context{
+			context("user", binary.ObjectValue{
+				context("id", 7),
+				context("tags", binary.ArrayValue{context("", "a"), context("", "b")}),
+			}),
+			context("empty", binary.ArrayValue{}),
+		},
+	}
+
+	text := string(FormatRecord(&record, iface.TextConfig{}))
+	want := "2024-05-06 07:08:09.000000 INFO  main <hello> (user: {id: 7, tags: [a, b]}) (empty: [])\n"
+	if text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+}
+
+func TestFormatHeader(t *testing.T) {
+	header := binary.FileHeader{
+		Time:       time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Hostname:   "host",
+		Pid:        42,
+		Executable: "/bin/app",
+		GoVersion:  "go1.23.0",
+		Logger:     "svc",
+		Version:    1,
+		Zone:       "CET",
+		ZoneOffset: 3600,
+		Previous:   "old.log.bin",
+	}
+	line := string(FormatHeader(&header, iface.TextConfig{}))
+	for _, want := range []string{"2024-05-06 08:08:09", "logger 'svc' on host by /bin/app (pid 42, go1.23.0)",
+		"format v1", "zone CET +01:00", "previous old.log.bin"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("header %q lacks %q", line, want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package text
+
+import (
+	"time"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// InZone returns tm in config.Location if set, or in the zone it was logged in.
+func InZone(tm time.Time, config *iface.TextConfig) time.Time {
+	if config.Location != nil {
+		return tm.In(config.Location)
+	}
+	return tm
+}
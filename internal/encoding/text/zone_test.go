@@ -0,0 +1,18 @@
+package text
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestInZone(t *testing.T) {
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("", 3600))
+	if got := InZone(tm, &iface.TextConfig{}); got.Format(time.RFC3339) != "2024-05-06T07:08:09+01:00" {
+		t.Errorf("time in its own zone = %v", got)
+	}
+	if got := InZone(tm, &iface.TextConfig{Location: time.UTC}); got.Format(time.RFC3339) != "2024-05-06T06:08:09Z" {
+		t.Errorf("time in UTC = %v", got)
+	}
+}
@@ -45,3 +45,39 @@ func (this *atomicBool) Set(b bool) {
 		atomic.StoreInt32(&this.value, 0)
 	}
 }
+
+type atomicLogger struct {
+	value atomic.Value
+}
+
+func newAtomicLogger(logger *Logger) *atomicLogger {
+	atom := new(atomicLogger)
+	atom.Set(logger)
+	return atom
+}
+
+func (this *atomicLogger) Get() *Logger {
+	return this.value.Load().(*Logger)
+}
+
+func (this *atomicLogger) Set(logger *Logger) {
+	this.value.Store(logger)
+}
+
+type atomicHooks struct {
+	value atomic.Value
+}
+
+func newAtomicHooks() *atomicHooks {
+	atom := new(atomicHooks)
+	atom.Set(nil)
+	return atom
+}
+
+func (this *atomicHooks) Get() []namedHook {
+	return this.value.Load().([]namedHook)
+}
+
+func (this *atomicHooks) Set(hooks []namedHook) {
+	this.value.Store(hooks)
+}
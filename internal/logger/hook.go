@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+)
+
+type Hook func(record *binary.Record) bool
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+func (this *Logger) AddHook(name string, hook Hook) error {
+	if name == "" {
+		return errors.New("glog: add hook: name is empty")
+	}
+	if hook == nil {
+		return errors.New("glog: add hook: hook is nil")
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.retired.Get() {
+		return errors.New("glog: add hook: logger is retired")
+	}
+	hooks := this.hooks.Get()
+	for _, h := range hooks {
+		if h.name == name {
+			return fmt.Errorf("glog: add hook: '%s' exists", name)
+		}
+	}
+
+	updated := make([]namedHook, 0, len(hooks)+1)
+	updated = append(updated, hooks...)
+	this.hooks.Set(append(updated, namedHook{name: name, hook: hook}))
+	return nil
+}
+
+func (this *Logger) RemoveHook(name string) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	hooks := this.hooks.Get()
+	for i, h := range hooks {
+		if h.name == name {
+			updated := make([]namedHook, 0, len(hooks)-1)
+			updated = append(updated, hooks[:i]...)
+			this.hooks.Set(append(updated, hooks[i+1:]...))
+			return true
+		}
+	}
+	return false
+}
+
+func (this *Logger) Hooks() []string {
+	hooks := this.hooks.Get()
+	names := make([]string, 0, len(hooks))
+	for _, h := range hooks {
+		names = append(names, h.name)
+	}
+	return names
+}
+
+// hookChain returns the effective logger and the hooks of the loggers up to it.
+func (this *Logger) hookChain() (*Logger, []namedHook) {
+	logger := this
+	chain := logger.hooks.Get()
+	for logger.inherit.Get() {
+		parent := logger.parent.Get()
+		if parent == nil {
+			break
+		}
+		logger = parent
+		if hooks := logger.hooks.Get(); len(hooks) != 0 {
+			chain = append(chain[:len(chain):len(chain)], hooks...)
+		}
+	}
+	return logger, chain
+}
+
+// runHooks passes the record of log through hooks; a panicking hook is skipped.
+func runHooks(log []byte, hooks []namedHook) (record *binary.Record, ok bool, err error) {
+	// Hooks may keep strings of the record, which refer to the decoded data,
+	// so decode a copy rather than the pooled buffer of the log.
+	record = new(binary.Record)
+	if _, err := binary.DecodeRecord(record, append([]byte(nil), log...)); err != nil {
+		return nil, false, fmt.Errorf("glog: corrupted log: %v", err)
+	}
+
+	for _, h := range hooks {
+		pass, hookErr := runHook(h, record)
+		if hookErr != nil && err == nil {
+			err = hookErr
+		}
+		if !pass {
+			return nil, false, err
+		}
+	}
+	return record, true, err
+}
+
+func runHook(h namedHook, record *binary.Record) (pass bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pass, err = true, fmt.Errorf("glog: hook '%s' panicked: %v", h.name, r)
+		}
+	}()
+	return h.hook(record), nil
+}
+
+// appendHooked encodes a record that hooks may have made illegal.
+func appendHooked(dst []byte, record *binary.Record) (log []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log, err = dst, fmt.Errorf("glog: drop hooked record: %v", r)
+		}
+	}()
+
+	log = binary.AppendRecord(dst, record)
+	if _, err := binary.DecodeRecord(new(binary.Record), log[len(dst):]); err != nil {
+		return dst, fmt.Errorf("glog: drop hooked record: %v", err)
+	}
+	return log, nil
+}
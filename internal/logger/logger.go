@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -11,20 +12,35 @@ import (
 )
 
 type Logger struct {
+	name          string
 	consoleWriter *console.Writer
 	fileWriter    *file.Writer
 
-	config   iface.Logger
-	level    *atomicLevel
-	fileLine *atomicBool
+	config     iface.Logger
+	level      *atomicLevel
+	stackLevel *atomicLevel
+	verbosity  *atomicVerbosity
+	fileLine   *atomicBool
+	fullPath   *atomicBool
+	funcName   *atomicBool
+	writing    *atomicBool
+
+	parent  *atomicLogger
+	inherit *atomicBool
+	retired *atomicBool
+
+	hooks   *atomicHooks
+	hookBuf []byte
+	seq     uint64
 
 	lock sync.Mutex
 }
 
-func New() *Logger {
+func New(name string) *Logger {
 	config := iface.Logger{
-		Level:    iface.Trace,
-		FileLine: true,
+		Level:      iface.Trace,
+		FileLine:   true,
+		StackLevel: iface.Error,
 		ConsoleWriter: iface.ConsoleWriter{
 			TextConfig: iface.TextConfig{
 				Coloring: true,
@@ -34,32 +50,88 @@ func New() *Logger {
 	}
 
 	consoleWriter := new(console.Writer)
-	if err := consoleWriter.SetConfig(config.ConsoleWriter); err != nil {
+	if err := consoleWriter.CheckConfig(config.ConsoleWriter); err != nil {
 		panic(fmt.Sprintf("glog: invalid default config for console writer: %v", err))
 	}
+	consoleWriter.SetConfig(config.ConsoleWriter)
 
 	return &Logger{
+		name:          name,
 		consoleWriter: consoleWriter,
-		fileWriter:    new(file.Writer),
+		fileWriter:    file.NewWriter(name),
 		config:        config,
 		level:         newAtomicLevel(config.Level),
+		stackLevel:    newAtomicLevel(stackLevel(&config)),
+		verbosity:     newAtomicVerbosity(config.Verbosity, config.VModules),
 		fileLine:      newAtomicBool(config.FileLine),
+		fullPath:      newAtomicBool(config.FullPath),
+		funcName:      newAtomicBool(config.FuncName),
+		writing:       newAtomicBool(config.ConsoleWriter.Enable || config.FileWriter.Enable),
+		parent:        newAtomicLogger(nil),
+		inherit:       newAtomicBool(false),
+		retired:       newAtomicBool(false),
+		hooks:         newAtomicHooks(),
 	}
 }
 
+func NewChild(name string, parent *Logger) *Logger {
+	if parent == nil {
+		panic("glog: new child logger: parent is nil")
+	}
+
+	logger := New(name)
+	logger.parent.Set(parent)
+	logger.inherit.Set(true)
+	return logger
+}
+
+func (this *Logger) Name() string {
+	return this.name
+}
+
+func (this *Logger) Parent() *Logger {
+	return this.parent.Get()
+}
+
+func (this *Logger) SetParent(parent *Logger) {
+	this.parent.Set(parent)
+}
+
+func (this *Logger) Inherited() bool {
+	return this.inherit.Get()
+}
+
 func (this *Logger) Level() iface.Level {
-	return this.level.Get()
+	return this.effective().level.Get()
+}
+
+func (this *Logger) StackLevel() iface.Level {
+	return this.effective().stackLevel.Get()
+}
+
+func (this *Logger) Verbosity(pkg string) int {
+	return this.effective().verbosity.Get(pkg)
 }
 
 func (this *Logger) FileLine() bool {
-	return this.fileLine.Get()
+	return this.effective().fileLine.Get()
+}
+
+func (this *Logger) FullPath() bool {
+	return this.effective().fullPath.Get()
+}
+
+func (this *Logger) FuncName() bool {
+	return this.effective().funcName.Get()
 }
 
 func (this *Logger) Config() iface.Logger {
-	this.lock.Lock()
-	defer this.lock.Unlock()
+	target := this.effective()
+
+	target.lock.Lock()
+	defer target.lock.Unlock()
 
-	return this.config
+	return target.config
 }
 
 func (this *Logger) SetConfig(config iface.Logger) error {
@@ -74,47 +146,245 @@ func (this *Logger) UpdateConfig(updater func(config iface.Logger) iface.Logger)
 		panic("glog: update config: updater is nil")
 	}
 
+	if this.inherit.Get() {
+		config := this.Config()
+
+		this.lock.Lock()
+		defer this.lock.Unlock()
+
+		return this.setConfig(updater(config))
+	}
+
 	this.lock.Lock()
 	defer this.lock.Unlock()
 
 	return this.setConfig(updater(this.config))
 }
 
-func (this *Logger) Commit(emit func(time.Time) []byte, done func()) {
-	this.lock.Lock()
+func (this *Logger) Inherit() error {
+	if this.parent.Get() == nil {
+		return errors.New("glog: inherit config: logger has no parent")
+	}
 
-	tm := time.Now()
-	log := emit(tm)
+	this.lock.Lock()
+	defer this.lock.Unlock()
 
-	if this.config.ConsoleWriter.Enable {
-		this.consoleWriter.Write(log, tm)
+	if this.retired.Get() {
+		return errors.New("glog: inherit config: logger is retired")
 	}
-	if this.config.FileWriter.Enable {
-		this.fileWriter.Write(log, tm)
+	if err := this.fileWriter.Close(); err != nil {
+		return fmt.Errorf("glog: inherit config: %v", err)
 	}
+	this.inherit.Set(true)
+	return nil
+}
+
+func (this *Logger) Close() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 
-	this.lock.Unlock()
+	return this.fileWriter.Close()
+}
 
-	done()
+// Retire closes the logger and forwards its logs to heir.
+func (this *Logger) Retire(heir *Logger) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.retired.Set(true)
+	this.parent.Set(heir)
+	this.inherit.Set(heir != nil)
+	this.level.Set(iface.Off)
+	this.writing.Set(false)
+	this.hooks.Set(nil)
+	return this.fileWriter.Close()
 }
 
-func (this *Logger) setConfig(config iface.Logger) error {
+// CheckConfig tells whether config is valid, without creating directories.
+func (this *Logger) CheckConfig(config iface.Logger) error {
 	level := config.Level
 	if !level.LegalForLogger() {
 		return fmt.Errorf("glog: set config: illegal logger level: %d", level)
 	}
+	if config.AutoStack && !config.StackLevel.LegalForLog() {
+		return fmt.Errorf("glog: set config: illegal stack level: %d", config.StackLevel)
+	}
+	if config.Verbosity < 0 {
+		return fmt.Errorf("glog: set config: illegal verbosity: %d", config.Verbosity)
+	}
+	for _, module := range config.VModules {
+		if !module.Legal() {
+			return fmt.Errorf("glog: set config: illegal vmodule: '%s=%d'", module.Pattern, module.Verbosity)
+		}
+	}
 
-	if err := this.consoleWriter.SetConfig(config.ConsoleWriter); err != nil {
+	if err := this.consoleWriter.CheckConfig(config.ConsoleWriter); err != nil {
 		return fmt.Errorf("glog: set config: invalid config for console writer: %v", err)
 	}
+	if err := this.fileWriter.CheckConfig(config.FileWriter); err != nil {
+		return fmt.Errorf("glog: set config: invalid config for file writer: %v", err)
+	}
+	return nil
+}
 
-	if err := this.fileWriter.SetConfig(config.FileWriter); err != nil {
+func MakeDir(config iface.Logger) error {
+	if err := file.MakeDir(config.FileWriter); err != nil {
 		return fmt.Errorf("glog: set config: invalid config for file writer: %v", err)
 	}
+	return nil
+}
+
+// ApplyConfigs sets checked configs to loggers, locking them in the given order.
+func ApplyConfigs(loggers []*Logger, configs []iface.Logger) error {
+	if len(loggers) != len(configs) {
+		panic("glog: apply configs: loggers and configs differ in number")
+	}
+
+	for _, logger := range loggers {
+		logger.lock.Lock()
+		defer logger.lock.Unlock()
+	}
+
+	var firstErr error
+	for i, logger := range loggers {
+		if logger.retired.Get() {
+			panic(fmt.Sprintf("glog: apply configs: logger '%s' is retired", logger.name))
+		}
+		if err := logger.applyConfig(configs[i]); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("'%s': %v", logger.name, err)
+		}
+	}
+	return firstErr
+}
+
+// Committing tells whether a log committed now would be written or hooked.
+func (this *Logger) Committing() bool {
+	logger := this
+	for len(logger.hooks.Get()) == 0 && logger.inherit.Get() {
+		parent := logger.parent.Get()
+		if parent == nil {
+			break
+		}
+		logger = parent
+	}
+	return len(logger.hooks.Get()) != 0 || logger.writing.Get()
+}
+
+// Commit numbers and writes a log, or passes it to the hooks first.
+func (this *Logger) Commit(emit func(time.Time, uint64) []byte, done func()) {
+	defer done()
+
+	target, hooks := this.hookChain()
+	if len(hooks) != 0 {
+		target.commitHooked(emit, hooks)
+		return
+	}
+
+	target.lock.Lock()
+	defer target.lock.Unlock()
+
+	if !target.writing.Get() {
+		return
+	}
+	tm := time.Now()
+	target.seq++
+	target.write(emit(tm, target.seq), tm)
+}
+
+// commitHooked runs the hooks without holding the lock, so that they may log.
+func (this *Logger) commitHooked(emit func(time.Time, uint64) []byte, hooks []namedHook) {
+	tm := time.Now()
+	record, ok, err := runHooks(emit(tm, 0), hooks)
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if err != nil {
+		this.handleError(tm, err)
+	}
+	if !ok || !this.writing.Get() {
+		return
+	}
+	record.Seq = this.seq + 1
+	log, err := appendHooked(this.hookBuf[:0], record)
+	this.hookBuf = log
+	if err != nil {
+		this.handleError(tm, err)
+		return
+	}
+	this.seq++
+	this.write(log, tm)
+}
+
+func (this *Logger) write(log []byte, tm time.Time) {
+	if this.config.ConsoleWriter.Enable {
+		this.consoleWriter.Write(log, tm)
+	}
+	if this.config.FileWriter.Enable {
+		this.fileWriter.Write(log, tm)
+	}
+}
+
+func (this *Logger) handleError(tm time.Time, err error) {
+	console, file := &this.config.ConsoleWriter, &this.config.FileWriter
+	if console.Enable && console.ErrorHandler != nil {
+		console.ErrorHandler(tm, err)
+	}
+	if file.Enable && file.ErrorHandler != nil {
+		file.ErrorHandler(tm, err)
+	}
+}
+
+func (this *Logger) effective() *Logger {
+	logger := this
+	for logger.inherit.Get() {
+		parent := logger.parent.Get()
+		if parent == nil {
+			break
+		}
+		logger = parent
+	}
+	return logger
+}
+
+func (this *Logger) setConfig(config iface.Logger) error {
+	if this.retired.Get() {
+		return errors.New("glog: set config: logger is retired")
+	}
+	if err := this.CheckConfig(config); err != nil {
+		return err
+	}
+	if err := MakeDir(config); err != nil {
+		return err
+	}
+	return this.applyConfig(config)
+}
+
+// applyConfig sets a config that has passed CheckConfig.
+func (this *Logger) applyConfig(config iface.Logger) error {
+	this.consoleWriter.SetConfig(config.ConsoleWriter)
+	err := this.fileWriter.SetConfig(config.FileWriter)
 
 	this.config = config
-	this.level.Set(level)
+	this.inherit.Set(false)
+	this.level.Set(config.Level)
+	this.stackLevel.Set(stackLevel(&config))
+	this.verbosity.Set(config.Verbosity, config.VModules)
 	this.fileLine.Set(config.FileLine)
+	this.fullPath.Set(config.FullPath)
+	this.funcName.Set(config.FuncName)
+	this.writing.Set(config.ConsoleWriter.Enable || config.FileWriter.Enable)
 
+	if err != nil {
+		return fmt.Errorf("glog: set config: file writer: %v", err)
+	}
 	return nil
 }
+
+func stackLevel(config *iface.Logger) iface.Level {
+	if config.AutoStack {
+		return config.StackLevel
+	} else {
+		return iface.Off
+	}
+}
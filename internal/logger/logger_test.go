@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// newQuiet creates a logger that writes nowhere.
+func newQuiet(t *testing.T, name string) *Logger {
+	t.Helper()
+
+	logger := New(name)
+	config := logger.Config()
+	config.ConsoleWriter.Enable = false
+	if err := logger.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+// commit commits a record with msg to logger.
+func commit(logger *Logger, msg string) {
+	logger.Commit(func(tm time.Time, seq uint64) []byte {
+		record := binary.Record{Time: tm, Seq: seq, Level: iface.Info, Pkg: "test", Msg: msg}
+		return binary.AppendRecord(nil, &record)
+	}, func() {})
+}
+
+func setLevel(t *testing.T, logger *Logger, level iface.Level) {
+	t.Helper()
+
+	err := logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Level = level
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInheritance(t *testing.T) {
+	root := newQuiet(t, "svc")
+	child := NewChild("svc.db", root)
+	grandchild := NewChild("svc.db.pool", child)
+
+	setLevel(t, root, iface.Warn)
+	if level := grandchild.Level(); level != iface.Warn {
+		t.Errorf("inherited level = %v, want WARN", level)
+	}
+	if grandchild.Config().ConsoleWriter.Enable {
+		t.Error("inherited config has the console writer enabled")
+	}
+
+	setLevel(t, child, iface.Error)
+	if child.Inherited() || !grandchild.Inherited() {
+		t.Error("UpdateConfig did not override the config of the child only")
+	}
+	if level := grandchild.Level(); level != iface.Error {
+		t.Errorf("level under an overriding child = %v, want ERROR", level)
+	}
+	if config := child.Config(); config.Level != iface.Error || config.ConsoleWriter.Enable {
+		t.Errorf("overridden config = %+v, want the parent's with level ERROR", config)
+	}
+	setLevel(t, root, iface.Debug)
+	if level := grandchild.Level(); level != iface.Error {
+		t.Errorf("parent update reached an overriding child: level %v", level)
+	}
+
+	if err := child.Inherit(); err != nil {
+		t.Fatal(err)
+	}
+	if level := grandchild.Level(); level != iface.Debug {
+		t.Errorf("level after Inherit = %v, want DEBUG", level)
+	}
+	if err := root.Inherit(); err == nil {
+		t.Error("Inherit succeeded without a parent")
+	}
+}
+
+func TestHookChain(t *testing.T) {
+	root := newQuiet(t, "svc")
+	child := NewChild("svc.db", root)
+
+	var calls []string
+	hook := func(name string) Hook {
+		return func(record *binary.Record) bool {
+			calls = append(calls, name+":"+record.Msg)
+			return record.Msg != "dropped by "+name
+		}
+	}
+	for _, h := range []struct {
+		logger *Logger
+		name   string
+	}{{child, "child1"}, {child, "child2"}, {root, "root"}} {
+		if err := h.logger.AddHook(h.name, hook(h.name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := child.AddHook("child1", hook("child1")); err == nil {
+		t.Error("duplicate hook added")
+	}
+
+	commit(child, "a")
+	commit(child, "dropped by child2")
+	commit(root, "b")
+	want := []string{
+		"child1:a", "child2:a", "root:a",
+		"child1:dropped by child2", "child2:dropped by child2",
+		"root:b",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %s, want %s", i, calls[i], want[i])
+		}
+	}
+
+	// A child with a config of its own runs only its own hooks.
+	setLevel(t, child, iface.Info)
+	calls = nil
+	commit(child, "c")
+	if len(calls) != 2 || calls[1] != "child2:c" {
+		t.Errorf("calls of an overriding child = %q", calls)
+	}
+
+	if !child.RemoveHook("child1") || child.RemoveHook("child1") {
+		t.Error("RemoveHook did not remove the hook once")
+	}
+	if names := child.Hooks(); len(names) != 1 || names[0] != "child2" {
+		t.Errorf("hooks = %q, want [child2]", names)
+	}
+}
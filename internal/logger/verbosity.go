@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+type verbosity struct {
+	level   int
+	modules []iface.VModule
+	cache   sync.Map
+}
+
+type atomicVerbosity struct {
+	value atomic.Value
+}
+
+func newAtomicVerbosity(level int, modules []iface.VModule) *atomicVerbosity {
+	atom := new(atomicVerbosity)
+	atom.Set(level, modules)
+	return atom
+}
+
+func (this *atomicVerbosity) Get(pkg string) int {
+	v := this.value.Load().(*verbosity)
+	if len(v.modules) == 0 {
+		return v.level
+	}
+	if level, ok := v.cache.Load(pkg); ok {
+		return level.(int)
+	}
+
+	level := v.level
+	for _, module := range v.modules {
+		if module.Match(pkg) {
+			level = module.Verbosity
+			break
+		}
+	}
+	v.cache.Store(pkg, level)
+	return level
+}
+
+func (this *atomicVerbosity) Set(level int, modules []iface.VModule) {
+	this.value.Store(&verbosity{
+		level:   level,
+		modules: append([]iface.VModule(nil), modules...),
+	})
+}
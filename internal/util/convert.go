@@ -1,16 +1,15 @@
 package util
 
 import (
-	"bytes"
-
 	"github.com/gratonos/glog/internal/encoding/binary"
 	"github.com/gratonos/glog/internal/encoding/text"
+	"github.com/gratonos/glog/pkg/glog/iface"
 )
 
-func BinaryToText(log []byte, coloring bool) ([]byte, error) {
+func BinaryToText(log []byte, config iface.TextConfig) ([]byte, error) {
 	var record binary.Record
-	if err := binary.ReadRecord(&record, bytes.NewBuffer(log)); err != nil {
+	if _, err := binary.DecodeRecord(&record, log); err != nil {
 		return nil, err
 	}
-	return text.FormatRecord(&record, coloring), nil
+	return text.FormatRecord(&record, config), nil
 }
@@ -19,7 +19,7 @@ func New(config iface.ConsoleWriter) *Writer {
 }
 
 func (this *Writer) Write(log []byte, tm time.Time) {
-	text, err := util.BinaryToText(log, this.config.TextConfig.Coloring)
+	text, err := util.BinaryToText(log, this.config.TextConfig)
 	if err != nil {
 		panic(fmt.Sprintf("glog: corrupted log: %v", err))
 	}
@@ -30,7 +30,14 @@ func (this *Writer) Write(log []byte, tm time.Time) {
 	}
 }
 
-func (this *Writer) SetConfig(config iface.ConsoleWriter) error {
-	this.config = config
+func (this *Writer) CheckConfig(config iface.ConsoleWriter) error {
+	if !config.TextConfig.BytesFormat.Legal() {
+		return fmt.Errorf("illegal BytesFormat '%d'", config.TextConfig.BytesFormat)
+	}
 	return nil
 }
+
+// SetConfig sets a config that has passed CheckConfig.
+func (this *Writer) SetConfig(config iface.ConsoleWriter) {
+	this.config = config
+}
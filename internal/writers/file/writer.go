@@ -6,9 +6,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"syscall"
 	"time"
 
+	"github.com/gratonos/glog/internal/encoding/binary"
 	"github.com/gratonos/glog/internal/util"
 	"github.com/gratonos/glog/pkg/glog/iface"
 
@@ -27,7 +29,17 @@ var Extensions = [...]string{
 	iface.Text:   ".log.txt",
 }
 
+// IndexExtension is the extension of the index of a binary log file.
+const IndexExtension = ".log.idx"
+
+// truncater is implemented by *os.File.
+type truncater interface {
+	Truncate(size int64) error
+	io.Seeker
+}
+
 type Writer struct {
+	name   string
 	config iface.FileWriter
 
 	writer    io.WriteCloser
@@ -35,14 +47,39 @@ type Writer struct {
 	checkTime time.Time
 	path      string
 	fileSize  int64
+
+	encoder  binary.Encoder
+	buf      []byte
+	index    io.WriteCloser
+	indexBuf []byte
+}
+
+// NewWriter creates a Writer for the logger with the given name, which is
+// recorded in the headers of binary log files.
+func NewWriter(name string) *Writer {
+	return &Writer{
+		name: name,
+	}
 }
 
 func (this *Writer) Write(log []byte, tm time.Time) {
 	err := this.checkFile(tm)
 	if err == nil {
+		offset := this.fileSize
+		indexing := this.index != nil && this.encoder.Restarting()
 		var n int
 		n, err = this.writer.Write(this.convert(log))
 		this.fileSize += int64(n)
+		if err != nil {
+			// The dictionary holds strings that were never written.
+			this.encoder.Reset()
+			if n > 0 {
+				this.cutOff(offset)
+			}
+		} else if indexing {
+			this.indexBuf = binary.AppendIndexEntry(this.indexBuf[:0], tm, offset)
+			_, err = this.index.Write(this.indexBuf)
+		}
 	}
 
 	if err != nil && this.config.ErrorHandler != nil {
@@ -50,45 +87,59 @@ func (this *Writer) Write(log []byte, tm time.Time) {
 	}
 }
 
-func (this *Writer) SetConfig(config iface.FileWriter) error {
+// CheckConfig tells whether config is valid, without creating its Dir.
+func (this *Writer) CheckConfig(config iface.FileWriter) error {
 	if !config.Enable {
-		return this.closeFile()
+		return nil
 	}
 	if !config.Format.Legal() {
 		return fmt.Errorf("illegal Format '%d'", config.Format)
 	}
-	if config.MaxFileSize <= 0 {
-		return errors.New("MaxFileSize must be positive")
+	if !config.TextConfig.BytesFormat.Legal() {
+		return fmt.Errorf("illegal BytesFormat '%d'", config.TextConfig.BytesFormat)
 	}
-	if err := this.checkDir(config.Dir); err != nil {
-		return err
+	if config.MaxFileSize < 0 {
+		return errors.New("MaxFileSize must not be negative")
+	}
+	if config.Dir == "" {
+		return errors.New("Dir is empty")
 	}
-	this.config = config
 	return nil
 }
 
-func (this *Writer) checkDir(dir string) error {
-	if dir == "" {
-		return errors.New("Dir is empty")
-	}
-	if dir == this.config.Dir {
+// MakeDir creates the Dir of config if it enables the writer.
+func MakeDir(config iface.FileWriter) error {
+	if !config.Enable {
 		return nil
 	}
-	if err := mkdir(dir); err != nil {
-		return err
-	}
-	if err := this.closeFile(); err != nil {
-		return err
+	return mkdir(config.Dir)
+}
+
+// SetConfig sets a config that has passed CheckConfig.
+func (this *Writer) SetConfig(config iface.FileWriter) error {
+	var err error
+	if !config.Enable || config.Dir != this.config.Dir || config.Index != this.config.Index {
+		err = this.closeFile()
 	}
-	return nil
+	this.config = config
+	return err
+}
+
+func (this *Writer) Close() error {
+	return this.closeFile()
 }
 
 func (this *Writer) convert(log []byte) []byte {
 	switch this.config.Format {
 	case iface.Binary:
-		return log
+		buf, err := this.encoder.Transcode(this.buf[:0], log)
+		if err != nil {
+			panic(fmt.Sprintf("glog: corrupted log: %v", err))
+		}
+		this.buf = buf
+		return buf
 	case iface.Text:
-		text, err := util.BinaryToText(log, this.config.TextConfig.Coloring)
+		text, err := util.BinaryToText(log, this.config.TextConfig)
 		if err != nil {
 			panic(fmt.Sprintf("glog: corrupted log: %v", err))
 		}
@@ -99,8 +150,9 @@ func (this *Writer) convert(log []byte) []byte {
 }
 
 func (this *Writer) checkFile(tm time.Time) error {
+	// A MaxFileSize of 0 leaves files unlimited in size.
 	if this.writer == nil ||
-		this.fileSize >= this.config.MaxFileSize ||
+		(this.config.MaxFileSize > 0 && this.fileSize >= this.config.MaxFileSize) ||
 		tm.Sub(this.nextDay) >= 0 {
 		return this.createFile(tm)
 	} else if tm.Sub(this.checkTime) >= checkInterval {
@@ -134,22 +186,90 @@ func (this *Writer) createFile(tm time.Time) error {
 		return err
 	}
 
+	previous := this.path
 	this.writer = file
+	this.encoder.Reset()
 	this.nextDay = nextDay(tm)
 	this.path = path
 	this.fileSize = 0
 
+	if this.config.Format == iface.Binary {
+		if err := this.writeHeader(tm, previous); err != nil {
+			return err
+		}
+	}
+
+	if this.config.Index && this.config.Format == iface.Binary {
+		// Logs can do without an index, so failing to create one is not fatal.
+		if err := this.createIndex(filepath.Join(dir, clockStr(tm)+IndexExtension)); err != nil &&
+			this.config.ErrorHandler != nil {
+			this.config.ErrorHandler(tm, err)
+		}
+	}
+
 	return nil
 }
 
+func (this *Writer) writeHeader(tm time.Time, previous string) error {
+	hostname, _ := os.Hostname()
+	executable, _ := os.Executable()
+	zone, offset := tm.Zone()
+	header := binary.FileHeader{
+		Time:       tm,
+		Hostname:   hostname,
+		Pid:        os.Getpid(),
+		Executable: executable,
+		GoVersion:  runtime.Version(),
+		Logger:     this.name,
+		Zone:       zone,
+		ZoneOffset: offset,
+		Previous:   previous,
+	}
+	this.buf = this.encoder.AppendHeader(this.buf[:0], &header)
+	n, err := this.writer.Write(this.buf)
+	this.fileSize += int64(n)
+	return err
+}
+
+func (this *Writer) createIndex(path string) error {
+	index, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	this.indexBuf = binary.AppendIndexHeader(this.indexBuf[:0])
+	if _, err := index.Write(this.indexBuf); err != nil {
+		index.Close()
+		return err
+	}
+	this.index = index
+	return nil
+}
+
+// cutOff removes a torn record at offset, or closes the file if it cannot.
+func (this *Writer) cutOff(offset int64) {
+	if file, ok := this.writer.(truncater); ok && file.Truncate(offset) == nil {
+		if _, err := file.Seek(offset, io.SeekStart); err == nil {
+			this.fileSize = offset
+			return
+		}
+	}
+	this.closeFile()
+}
+
+// closeFile closes the current file and its index.
 func (this *Writer) closeFile() error {
+	var err error
+	if this.index != nil {
+		err = this.index.Close()
+		this.index = nil
+	}
 	if this.writer != nil {
-		if err := this.writer.Close(); err != nil {
-			return err
+		if closeErr := this.writer.Close(); err == nil {
+			err = closeErr
 		}
 		this.writer = nil
 	}
-	return nil
+	return err
 }
 
 func nextDay(tm time.Time) time.Time {
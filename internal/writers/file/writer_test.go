@@ -0,0 +1,155 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestMaxFileSize(t *testing.T) {
+	tests := []struct {
+		maxFileSize int64
+		files       int
+	}{
+		{maxFileSize: 0, files: 1},
+		{maxFileSize: 1, files: 10},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		writer := NewWriter("test")
+		config := iface.FileWriter{Enable: true, Dir: dir, Format: iface.Text, MaxFileSize: test.maxFileSize}
+		if err := writer.CheckConfig(config); err != nil {
+			t.Fatal(err)
+		}
+		writer.SetConfig(config)
+
+		tm := time.Now()
+		for i := 0; i < 10; i++ {
+			// Each file is named after the time of its first record.
+			tm = tm.Add(time.Microsecond)
+			record := binary.Record{Time: tm, Level: iface.Info, Pkg: "test", Msg: "message"}
+			writer.Write(binary.AppendRecord(nil, &record), tm)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		files, _ := filepath.Glob(filepath.Join(dir, "*", "*"+Extensions[iface.Text]))
+		if len(files) != test.files {
+			t.Errorf("MaxFileSize %d: %d files, want %d", test.maxFileSize, len(files), test.files)
+		}
+	}
+
+	if err := NewWriter("test").CheckConfig(iface.FileWriter{Enable: true, Dir: t.TempDir(), MaxFileSize: -1}); err == nil {
+		t.Error("negative MaxFileSize accepted")
+	}
+}
+
+// failingFile fails the next write after writing part of it.
+type failingFile struct {
+	*os.File
+	fail bool
+}
+
+func (this *failingFile) Write(data []byte) (int, error) {
+	if this.fail {
+		this.fail = false
+		n, _ := this.File.Write(data[:len(data)/2])
+		return n, errors.New("write failed")
+	}
+	return this.File.Write(data)
+}
+
+func TestWriteError(t *testing.T) {
+	dir := t.TempDir()
+	writer := NewWriter("test")
+	config := iface.FileWriter{Enable: true, Dir: dir, Format: iface.Binary}
+	if err := writer.CheckConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	var errs []error
+	config.ErrorHandler = func(tm time.Time, err error) { errs = append(errs, err) }
+	writer.SetConfig(config)
+
+	write := func(pkg string) {
+		tm := time.Now()
+		record := binary.Record{Time: tm, Level: iface.Info, Pkg: pkg, Msg: pkg}
+		writer.Write(binary.AppendRecord(nil, &record), tm)
+	}
+	write("first")
+	file := &failingFile{File: writer.writer.(*os.File), fail: true}
+	writer.writer = file
+	// The failed record defines pkg "second", which the next one refers to.
+	write("second")
+	write("second")
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("got errors %v, want one", errs)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := binary.NewBytesReader(data)
+	var record binary.Record
+	var msgs []string
+	for {
+		err := reader.Read(&record)
+		if err == binary.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, record.Msg)
+	}
+	if len(msgs) != 2 || msgs[0] != "first" || msgs[1] != "second" {
+		t.Errorf("got %q, want [first second]", msgs)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	dir := t.TempDir()
+	writer := NewWriter("svc")
+	config := iface.FileWriter{Enable: true, Dir: dir, Format: iface.Binary, MaxFileSize: 1}
+	if err := writer.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	tm := time.Now()
+	for i := 0; i < 2; i++ {
+		tm = tm.Add(time.Microsecond)
+		record := binary.Record{Time: tm, Level: iface.Info, Pkg: "test", Msg: "message"}
+		writer.Write(binary.AppendRecord(nil, &record), tm)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*", "*"+Extensions[iface.Binary]))
+	if len(paths) != 2 {
+		t.Fatalf("got %d files, want 2", len(paths))
+	}
+	previous := ""
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := binary.NewBytesReader(data).ReadHeader()
+		if header == nil {
+			t.Fatalf("%s has no header", path)
+		}
+		if header.Logger != "svc" || header.Pid != os.Getpid() || header.Previous != previous {
+			t.Errorf("header of %s = %+v, want previous %q", path, header, previous)
+		}
+		previous = path
+	}
+}
@@ -0,0 +1,82 @@
+package glog
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	ilog "github.com/gratonos/glog/internal/logger"
+	"github.com/gratonos/glog/pkg/glog/iface"
+	"github.com/gratonos/glog/pkg/glog/logger"
+)
+
+var (
+	defaultLogger atomic.Value
+	defaultName   string
+)
+
+func init() {
+	SetDefault("")
+}
+
+func SetDefault(name string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	setDefault(name)
+}
+
+func setDefault(name string) {
+	defaultName = name
+	defaultLogger.Store(getLogger(name))
+}
+
+func Default() *logger.Logger {
+	return logger.NewLogger(getDefault(), callerPkg(0+1))
+}
+
+func V(verbosity int) *logger.Logger {
+	return logger.NewLogger(getDefault(), callerPkg(0+1)).V(verbosity)
+}
+
+func Log(level iface.Level, frameSkip int) *logger.Log {
+	if !level.LegalForLog() {
+		panic(fmt.Sprintf("glog: illegal log level: %d", level))
+	}
+	return defaultLog(level, frameSkip+1)
+}
+
+func Trace() *logger.Log {
+	return defaultLog(iface.Trace, 0+1)
+}
+
+func Debug() *logger.Log {
+	return defaultLog(iface.Debug, 0+1)
+}
+
+func Info() *logger.Log {
+	return defaultLog(iface.Info, 0+1)
+}
+
+func Warn() *logger.Log {
+	return defaultLog(iface.Warn, 0+1)
+}
+
+func Error() *logger.Log {
+	return defaultLog(iface.Error, 0+1)
+}
+
+func Fatal() *logger.Log {
+	return defaultLog(iface.Fatal, 0+1)
+}
+
+func getDefault() *ilog.Logger {
+	return defaultLogger.Load().(*ilog.Logger)
+}
+
+func defaultLog(level iface.Level, frameSkip int) *logger.Log {
+	ilogger := getDefault()
+	if ilogger.Level() > level {
+		return nil
+	}
+	return logger.NewLog(ilogger, level, callerPkg(frameSkip+1), frameSkip+1)
+}
@@ -0,0 +1,121 @@
+package glog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/internal/writers/file"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// useFiles makes the named logger write binary log files into a temporary
+// directory, which is returned, and restores its config when the test ends.
+func useFiles(t *testing.T, name string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	saved := Logger(name).Config()
+	err := Logger(name).UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.ConsoleWriter.Enable = false
+		config.FileWriter.Enable = true
+		config.FileWriter.Format = iface.Binary
+		config.FileWriter.Dir = dir
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Logger(name).SetConfig(saved) })
+	return dir
+}
+
+// readMsgs returns the pkg and msg of each record written into dir.
+func readMsgs(t *testing.T, dir string) (pkgs, msgs []string) {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*"+file.Extensions[iface.Binary]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader := binary.NewBytesReader(data)
+		var record binary.Record
+		for {
+			err := reader.Read(&record)
+			if err == binary.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read %s: %v", path, err)
+			}
+			pkgs = append(pkgs, record.Pkg)
+			msgs = append(msgs, record.Msg)
+		}
+	}
+	return pkgs, msgs
+}
+
+func TestDefault(t *testing.T) {
+	dir := useFiles(t, "default")
+	SetDefault("default")
+	defer SetDefault("")
+
+	err := Logger("default").UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Level = iface.Info
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Info().Commit("info")
+	if Debug() != nil {
+		t.Error("Debug enabled at level Info")
+	}
+	Default().Warn().Commit("warn")
+	V(1).Info().Commit("v1")
+	Log(iface.Error, 0).Commit("error")
+	err = Logger("default").UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Level = iface.Error
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Warn() != nil {
+		t.Error("Warn enabled at level Error")
+	}
+
+	SetDefault("")
+	if getDefault() != internalLogger("") {
+		t.Error("SetDefault did not switch the default logger")
+	}
+
+	pkgs, msgs := readMsgs(t, dir)
+	want := []string{"info", "warn", "error"}
+	if len(msgs) != len(want) {
+		t.Fatalf("got %q, want %q", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] || pkgs[i] != "github.com/gratonos/glog/pkg/glog" {
+			t.Errorf("record %d = %s from %s, want %s", i, msgs[i], pkgs[i], want[i])
+		}
+	}
+}
+
+func TestDefaultAllocs(t *testing.T) {
+	useFiles(t, "default")
+	SetDefault("default")
+	defer SetDefault("")
+
+	Info().Commit("warm up")
+	if allocs := testing.AllocsPerRun(100, func() { Info().Int("n", 1).Commit("info") }); allocs != 0 {
+		t.Errorf("%v allocs per record, want 0", allocs)
+	}
+}
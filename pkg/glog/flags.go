@@ -0,0 +1,99 @@
+package glog
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	ilog "github.com/gratonos/glog/internal/logger"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+type configFlag struct {
+	logger *ilog.Logger
+	get    func(config *iface.Logger) string
+	set    func(config *iface.Logger, value string) error
+}
+
+func RegisterFlags(fs *flag.FlagSet, name string) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	logger := internalLogger(name)
+	prefix := "log-"
+	if name != "" {
+		prefix = name + "-log-"
+	}
+
+	fs.Var(&configFlag{logger: logger, get: getLevel, set: setLevel},
+		prefix+"level", "log level: trace, debug, info, warn, error, fatal or off")
+	fs.Var(&configFlag{logger: logger, get: getDir, set: setDir},
+		prefix+"dir", "directory of log files, empty to disable the file writer")
+	fs.Var(&configFlag{logger: logger, get: getFormat, set: setFormat},
+		prefix+"format", "format of log files: binary or text")
+	fs.Var(&configFlag{logger: logger, get: getMaxFileSize, set: setMaxFileSize},
+		prefix+"max-size", "max size of a log file in bytes, 0 for unlimited")
+}
+
+func (this *configFlag) String() string {
+	if this == nil || this.logger == nil {
+		return ""
+	}
+	config := this.logger.Config()
+	return this.get(&config)
+}
+
+func (this *configFlag) Set(value string) error {
+	var setErr error
+	err := this.logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		updated := config
+		if setErr = this.set(&updated, value); setErr != nil {
+			return config
+		}
+		return updated
+	})
+	if setErr != nil {
+		return setErr
+	}
+	return err
+}
+
+func getLevel(config *iface.Logger) string {
+	return config.Level.String()
+}
+
+func setLevel(config *iface.Logger, value string) error {
+	return config.Level.Set(value)
+}
+
+func getDir(config *iface.Logger) string {
+	return config.FileWriter.Dir
+}
+
+func setDir(config *iface.Logger, value string) error {
+	config.FileWriter.Dir = value
+	config.FileWriter.Enable = value != ""
+	return nil
+}
+
+func getFormat(config *iface.Logger) string {
+	return config.FileWriter.Format.String()
+}
+
+func setFormat(config *iface.Logger, value string) error {
+	return config.FileWriter.Format.Set(value)
+}
+
+func getMaxFileSize(config *iface.Logger) string {
+	return strconv.FormatInt(config.FileWriter.MaxFileSize, 10)
+}
+
+func setMaxFileSize(config *iface.Logger, value string) error {
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("glog: parse max file size: %v", err)
+	}
+	config.FileWriter.MaxFileSize = size
+	return nil
+}
@@ -0,0 +1,56 @@
+package glog
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterFlags(fs, "flags")
+
+	if value := fs.Lookup("flags-log-max-size").Value.String(); value != "0" {
+		t.Errorf("default max size = %s, want 0", value)
+	}
+
+	dir := t.TempDir()
+	err := fs.Parse([]string{
+		"-flags-log-level=Warning",
+		"-flags-log-dir=" + dir,
+		"-flags-log-format=TEXT",
+		"-flags-log-max-size=1000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Remove("flags")
+
+	config := Logger("flags").Config()
+	if config.Level != iface.Warn || !config.FileWriter.Enable || config.FileWriter.Dir != dir ||
+		config.FileWriter.Format != iface.Text || config.FileWriter.MaxFileSize != 1000 {
+		t.Errorf("config = %+v", config)
+	}
+	if value := fs.Lookup("flags-log-level").Value.String(); value != "WARN" {
+		t.Errorf("level flag = %s, want WARN", value)
+	}
+
+	for _, arg := range []string{"-flags-log-level=bogus", "-flags-log-format=xml", "-flags-log-max-size=-1"} {
+		if err := fs.Parse([]string{arg}); err == nil {
+			t.Errorf("%s accepted", arg)
+		}
+	}
+	if Logger("flags").Config().Level != iface.Warn {
+		t.Error("config changed by a rejected flag")
+	}
+
+	if err := fs.Parse([]string{"-flags-log-dir="}); err != nil {
+		t.Fatal(err)
+	}
+	if Logger("flags").Config().FileWriter.Enable {
+		t.Error("file writer enabled without a dir")
+	}
+}
@@ -0,0 +1,15 @@
+package iface
+
+type BytesFormat uint8
+
+const (
+	BytesHex BytesFormat = iota
+	BytesBase64
+	BytesQuoted
+
+	bytesFormatBound
+)
+
+func (self BytesFormat) Legal() bool {
+	return self < bytesFormatBound
+}
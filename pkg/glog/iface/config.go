@@ -1,8 +1,18 @@
 package iface
 
+import (
+	"time"
+)
+
 type Logger struct {
 	Level         Level
+	Verbosity     int
+	VModules      []VModule
 	FileLine      bool
+	FullPath      bool
+	FuncName      bool
+	AutoStack     bool
+	StackLevel    Level
 	ConsoleWriter ConsoleWriter
 	FileWriter    FileWriter
 }
@@ -18,10 +28,14 @@ type FileWriter struct {
 	Format       Format
 	TextConfig   TextConfig
 	MaxFileSize  int64
+	Index        bool
 	Dir          string
 	ErrorHandler ErrorHandler
 }
 
 type TextConfig struct {
-	Coloring bool
+	Coloring    bool
+	BytesFormat BytesFormat
+	BytesLimit  int
+	Location    *time.Location
 }
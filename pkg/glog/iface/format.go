@@ -1,5 +1,10 @@
 package iface
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Format uint8
 
 const (
@@ -9,6 +14,47 @@ const (
 	formatBound
 )
 
+var formatNames = [...]string{
+	Binary: "binary",
+	Text:   "text",
+}
+
 func (self Format) Legal() bool {
 	return self < formatBound
 }
+
+func (self Format) String() string {
+	if !self.Legal() {
+		return fmt.Sprintf("Format(%d)", uint8(self))
+	}
+	return formatNames[self]
+}
+
+func (self Format) MarshalText() ([]byte, error) {
+	if !self.Legal() {
+		return nil, fmt.Errorf("glog: marshal format: illegal format: %d", self)
+	}
+	return []byte(formatNames[self]), nil
+}
+
+func (self *Format) UnmarshalText(text []byte) error {
+	format, err := ParseFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*self = format
+	return nil
+}
+
+func (self *Format) Set(str string) error {
+	return self.UnmarshalText([]byte(str))
+}
+
+func ParseFormat(str string) (Format, error) {
+	for format, name := range formatNames {
+		if strings.EqualFold(str, name) {
+			return Format(format), nil
+		}
+	}
+	return formatBound, fmt.Errorf("glog: parse format: unknown format '%s'", str)
+}
@@ -0,0 +1,28 @@
+package iface
+
+import (
+	"testing"
+)
+
+func TestFormatText(t *testing.T) {
+	for format := Binary; format < formatBound; format++ {
+		text, err := format.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var parsed Format
+		if err := parsed.Set(string(text)); err != nil || parsed != format {
+			t.Errorf("%s round-tripped to %v, %v", text, parsed, err)
+		}
+	}
+
+	if format, err := ParseFormat("TEXT"); err != nil || format != Text {
+		t.Errorf("ParseFormat(TEXT) = %v, %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("unknown format parsed")
+	}
+	if _, err := formatBound.MarshalText(); err == nil {
+		t.Error("illegal format marshaled")
+	}
+}
@@ -1,5 +1,10 @@
 package iface
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Level int32
 
 const (
@@ -12,6 +17,16 @@ const (
 	Off
 )
 
+var levelNames = [...]string{
+	Trace: "TRACE",
+	Debug: "DEBUG",
+	Info:  "INFO",
+	Warn:  "WARN",
+	Error: "ERROR",
+	Fatal: "FATAL",
+	Off:   "OFF",
+}
+
 func (self Level) LegalForLog() bool {
 	return self >= Trace && self <= Fatal
 }
@@ -19,3 +34,42 @@ func (self Level) LegalForLog() bool {
 func (self Level) LegalForLogger() bool {
 	return self.LegalForLog() || self == Off
 }
+
+func (self Level) String() string {
+	if !self.LegalForLogger() {
+		return fmt.Sprintf("Level(%d)", int32(self))
+	}
+	return levelNames[self]
+}
+
+func (self Level) MarshalText() ([]byte, error) {
+	if !self.LegalForLogger() {
+		return nil, fmt.Errorf("glog: marshal level: illegal level: %d", self)
+	}
+	return []byte(levelNames[self]), nil
+}
+
+func (self *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*self = level
+	return nil
+}
+
+func (self *Level) Set(str string) error {
+	return self.UnmarshalText([]byte(str))
+}
+
+func ParseLevel(str string) (Level, error) {
+	if strings.EqualFold(str, "warning") {
+		return Warn, nil
+	}
+	for level, name := range levelNames {
+		if strings.EqualFold(str, name) {
+			return Level(level), nil
+		}
+	}
+	return Off, fmt.Errorf("glog: parse level: unknown level '%s'", str)
+}
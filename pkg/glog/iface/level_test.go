@@ -0,0 +1,50 @@
+package iface
+
+import (
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"trace":   Trace,
+		"DEBUG":   Debug,
+		"Info":    Info,
+		"warn":    Warn,
+		"Warning": Warn,
+		"error":   Error,
+		"fatal":   Fatal,
+		"off":     Off,
+	}
+	for str, want := range tests {
+		if level, err := ParseLevel(str); err != nil || level != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", str, level, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("unknown level parsed")
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	for level := Trace; level <= Off; level++ {
+		text, err := level.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var parsed Level
+		if err := parsed.UnmarshalText(text); err != nil || parsed != level {
+			t.Errorf("%s round-tripped to %v, %v", text, parsed, err)
+		}
+	}
+
+	if _, err := Level(42).MarshalText(); err == nil {
+		t.Error("illegal level marshaled")
+	}
+	if str := Level(42).String(); str != "Level(42)" {
+		t.Errorf("illegal level = %q", str)
+	}
+	level := Info
+	if err := level.Set("bogus"); err == nil || level != Info {
+		t.Errorf("Set(bogus) = %v, level %v", err, level)
+	}
+}
@@ -0,0 +1,37 @@
+package iface
+
+import (
+	"path"
+	"strings"
+)
+
+type VModule struct {
+	Pattern   string
+	Verbosity int
+}
+
+func (self VModule) Legal() bool {
+	if self.Verbosity < 0 {
+		return false
+	}
+	_, err := path.Match(strings.TrimSuffix(self.Pattern, "/..."), "")
+	return self.Pattern != "" && err == nil
+}
+
+func (self VModule) Match(pkg string) bool {
+	pattern := self.Pattern
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+		for {
+			if ok, _ := path.Match(prefix, pkg); ok {
+				return true
+			}
+			slash := strings.LastIndexByte(pkg, '/')
+			if slash < 0 {
+				return false
+			}
+			pkg = pkg[:slash]
+		}
+	}
+	ok, _ := path.Match(pattern, pkg)
+	return ok
+}
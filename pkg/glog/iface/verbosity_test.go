@@ -0,0 +1,46 @@
+package iface
+
+import (
+	"testing"
+)
+
+func TestVModuleMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		pkg     string
+		want    bool
+	}{
+		{"main", "main", true},
+		{"main", "mainly", false},
+		{"github.com/a/*", "github.com/a/b", true},
+		{"github.com/a/*", "github.com/a/b/c", false},
+		{"github.com/a/...", "github.com/a", true},
+		{"github.com/a/...", "github.com/a/b/c", true},
+		{"github.com/a/...", "github.com/ab", false},
+		{"*/b/...", "a/b/c", true},
+	}
+	for _, test := range tests {
+		module := VModule{Pattern: test.pattern, Verbosity: 1}
+		if got := module.Match(test.pkg); got != test.want {
+			t.Errorf("%q matching %q = %v, want %v", test.pattern, test.pkg, got, test.want)
+		}
+	}
+}
+
+func TestVModuleLegal(t *testing.T) {
+	tests := []struct {
+		module VModule
+		want   bool
+	}{
+		{VModule{"main", 0}, true},
+		{VModule{"a/...", 3}, true},
+		{VModule{"", 1}, false},
+		{VModule{"[", 1}, false},
+		{VModule{"main", -1}, false},
+	}
+	for _, test := range tests {
+		if got := test.module.Legal(); got != test.want {
+			t.Errorf("%+v legal = %v, want %v", test.module, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// logTen commits a record with ten contexts of assorted kinds.
+func logTen(logger *Logger) {
+	logger.Info().
+		Str("k1", "v").
+		Int("k2", 1).
+		Int64("k3", 2).
+		Uint("k4", 3).
+		Bool("k5", true).
+		Float64("k6", 1.5).
+		Duration("k7", time.Second).
+		Time("k8", time.Time{}).
+		Str("k9", "x").
+		Int32("k10", 5).
+		Commit("hello")
+}
+
+// newAllocLogger creates a logger writing binary log files, configured by
+// update.
+func newAllocLogger(t testing.TB, update func(config *iface.Logger)) *Logger {
+	t.Helper()
+
+	logger, _ := newTestLogger(t)
+	err := logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		update(&config)
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+var allocCases = []struct {
+	name   string
+	update func(config *iface.Logger)
+}{
+	{"Plain", func(config *iface.Logger) { config.FileLine = false }},
+	{"FileLine", func(config *iface.Logger) { config.FileLine = true }},
+	{"FullPath", func(config *iface.Logger) { config.FileLine, config.FullPath = true, true }},
+	{"FuncName", func(config *iface.Logger) { config.FileLine, config.FuncName = true, true }},
+	{"Disabled", func(config *iface.Logger) { config.Level = iface.Warn }},
+}
+
+func TestAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not stable under the race detector")
+	}
+	for _, test := range allocCases {
+		logger := newAllocLogger(t, test.update)
+		logTen(logger) // warm up the pool, the caches and the file
+		// Each run logs past a dictionary restart.
+		allocs := testing.AllocsPerRun(10, func() {
+			for i := 0; i <= binary.DictResetInterval; i++ {
+				logTen(logger)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%s: %v allocs per %d records, want 0", test.name, allocs, binary.DictResetInterval+1)
+		}
+	}
+}
+
+func BenchmarkCommit(b *testing.B) {
+	for _, test := range allocCases {
+		b.Run(test.name, func(b *testing.B) {
+			logger := newAllocLogger(b, test.update)
+			logTen(logger)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logTen(logger)
+			}
+		})
+	}
+}
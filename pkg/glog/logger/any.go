@@ -0,0 +1,365 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+)
+
+const (
+	maxAnyDepth = 8
+	maxAnyElems = 256
+	maxAnyBytes = 16 << 10
+	nilValue    = "<nil>"
+	largeValue  = "<too large>"
+	moreKey     = "..."
+)
+
+// anyState is the budget shared by all the values nested in one passed to Any.
+type anyState struct {
+	active bool
+	full   bool
+	depth  int
+	elems  int
+	limit  int
+}
+
+func (this *Log) Any(key string, value interface{}) *Log {
+	if this != nil {
+		this.appendAnyValue(key, value)
+	}
+	return this
+}
+
+func (this *ObjectEncoder) Any(key string, value interface{}) *ObjectEncoder {
+	(*Log)(this).appendAnyValue(key, value)
+	return this
+}
+
+func (this *ArrayEncoder) Any(value interface{}) *ArrayEncoder {
+	(*Log)(this).appendAnyValue("", value)
+	return this
+}
+
+func (this *Log) appendAnyValue(key string, value interface{}) {
+	if this.any.active {
+		this.appendAny(key, value)
+		return
+	}
+	size := len(this.buf)
+	this.any = anyState{
+		active: true,
+		elems:  maxAnyElems,
+		limit:  size + maxAnyBytes,
+	}
+	this.appendAny(key, value)
+	if !this.any.full && len(this.buf) > this.any.limit {
+		this.buf = binary.AppendStringContext(this.buf[:size], key, largeValue)
+	}
+	this.any.active = false
+}
+
+func (this *Log) appendAny(key string, value interface{}) {
+	// Methods of the interfaces below are not expected to take nil pointers.
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		this.buf = binary.AppendStringContext(this.buf, key, nilValue)
+		return
+	}
+
+	switch v := value.(type) {
+	case nil:
+		this.buf = binary.AppendStringContext(this.buf, key, nilValue)
+	case bool:
+		this.buf = binary.AppendBoolContext(this.buf, key, v)
+	case int:
+		this.buf = binary.AppendInt64Context(this.buf, key, int64(v))
+	case int8:
+		this.buf = binary.AppendInt8Context(this.buf, key, v)
+	case int16:
+		this.buf = binary.AppendInt16Context(this.buf, key, v)
+	case int32:
+		this.buf = binary.AppendInt32Context(this.buf, key, v)
+	case int64:
+		this.buf = binary.AppendInt64Context(this.buf, key, v)
+	case uint:
+		this.buf = binary.AppendUint64Context(this.buf, key, uint64(v))
+	case uint8:
+		this.buf = binary.AppendUint8Context(this.buf, key, v)
+	case uint16:
+		this.buf = binary.AppendUint16Context(this.buf, key, v)
+	case uint32:
+		this.buf = binary.AppendUint32Context(this.buf, key, v)
+	case uint64:
+		this.buf = binary.AppendUint64Context(this.buf, key, v)
+	case uintptr:
+		this.buf = binary.AppendUintptrContext(this.buf, key, v)
+	case float32:
+		this.buf = binary.AppendFloat32Context(this.buf, key, v)
+	case float64:
+		this.buf = binary.AppendFloat64Context(this.buf, key, v)
+	case complex64:
+		this.buf = binary.AppendComplex64Context(this.buf, key, v)
+	case complex128:
+		this.buf = binary.AppendComplex128Context(this.buf, key, v)
+	case string:
+		this.buf = binary.AppendStringContext(this.buf, key, v)
+	case []byte:
+		this.buf = binary.AppendBytesContext(this.buf, key, v)
+	case time.Time:
+		this.buf = binary.AppendTimeContext(this.buf, key, v)
+	case time.Duration:
+		this.buf = binary.AppendDurationContext(this.buf, key, v)
+	case LogObjectMarshaler:
+		this.appendGuarded(key, "MarshalLogObject", func() {
+			this.appendObject(key, v)
+		})
+	case LogArrayMarshaler:
+		this.appendGuarded(key, "MarshalLogArray", func() {
+			this.appendArray(key, v)
+		})
+	case error:
+		this.buf = binary.AppendErrorContext(this.buf, key, v)
+	case fmt.Stringer:
+		this.appendGuarded(key, "String", func() {
+			this.buf = binary.AppendStringContext(this.buf, key, v.String())
+		})
+	case encoding.TextMarshaler:
+		this.appendGuarded(key, "MarshalText", func() {
+			text, err := v.MarshalText()
+			this.appendMarshaled(key, text, err)
+		})
+	case json.Marshaler:
+		this.appendGuarded(key, "MarshalJSON", func() {
+			text, err := v.MarshalJSON()
+			this.appendMarshaled(key, text, err)
+		})
+	default:
+		this.appendReflect(key, reflect.ValueOf(value))
+	}
+}
+
+// appendGuarded calls encode, logging a panic in its place as fmt does.
+func (this *Log) appendGuarded(key, method string, encode func()) {
+	size, nesting, depth := len(this.buf), this.nesting, this.any.depth
+	defer func() {
+		if r := recover(); r != nil {
+			this.buf = binary.AppendStringContext(this.buf[:size], key,
+				fmt.Sprintf("%%!v(PANIC=%s method: %v)", method, r))
+			this.nesting, this.any.depth = nesting, depth
+		}
+	}()
+	encode()
+}
+
+func (this *Log) appendMarshaled(key string, text []byte, err error) {
+	if err != nil {
+		this.buf = binary.AppendErrorContext(this.buf, key, err)
+	} else {
+		this.buf = binary.AppendStringContext(this.buf, key, string(text))
+	}
+}
+
+func (this *Log) appendReflect(key string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Bool:
+		this.buf = binary.AppendBoolContext(this.buf, key, value.Bool())
+	case reflect.Int, reflect.Int64:
+		this.buf = binary.AppendInt64Context(this.buf, key, value.Int())
+	case reflect.Int8:
+		this.buf = binary.AppendInt8Context(this.buf, key, int8(value.Int()))
+	case reflect.Int16:
+		this.buf = binary.AppendInt16Context(this.buf, key, int16(value.Int()))
+	case reflect.Int32:
+		this.buf = binary.AppendInt32Context(this.buf, key, int32(value.Int()))
+	case reflect.Uint, reflect.Uint64:
+		this.buf = binary.AppendUint64Context(this.buf, key, value.Uint())
+	case reflect.Uint8:
+		this.buf = binary.AppendUint8Context(this.buf, key, uint8(value.Uint()))
+	case reflect.Uint16:
+		this.buf = binary.AppendUint16Context(this.buf, key, uint16(value.Uint()))
+	case reflect.Uint32:
+		this.buf = binary.AppendUint32Context(this.buf, key, uint32(value.Uint()))
+	case reflect.Uintptr:
+		this.buf = binary.AppendUintptrContext(this.buf, key, uintptr(value.Uint()))
+	case reflect.Float32:
+		this.buf = binary.AppendFloat32Context(this.buf, key, float32(value.Float()))
+	case reflect.Float64:
+		this.buf = binary.AppendFloat64Context(this.buf, key, value.Float())
+	case reflect.Complex64:
+		this.buf = binary.AppendComplex64Context(this.buf, key, complex64(value.Complex()))
+	case reflect.Complex128:
+		this.buf = binary.AppendComplex128Context(this.buf, key, value.Complex())
+	case reflect.String:
+		this.buf = binary.AppendStringContext(this.buf, key, value.String())
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			this.buf = binary.AppendStringContext(this.buf, key, nilValue)
+		} else {
+			this.appendNested(key, value.Elem())
+		}
+	case reflect.Struct:
+		this.appendStruct(key, value)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			this.buf = binary.AppendStringContext(this.buf, key, nilValue)
+		} else if value.Type().Elem().Kind() == reflect.Uint8 {
+			this.buf = binary.AppendBytesContext(this.buf, key, reflectBytes(value))
+		} else {
+			this.appendSlice(key, value)
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			this.buf = binary.AppendStringContext(this.buf, key, nilValue)
+		} else {
+			this.appendMap(key, value)
+		}
+	default:
+		this.buf = binary.AppendStringContext(this.buf, key, fmt.Sprint(value))
+	}
+}
+
+func (this *Log) appendNested(key string, value reflect.Value) {
+	if this.any.depth >= maxAnyDepth {
+		this.buf = binary.AppendStringContext(this.buf, key, value.Type().String())
+		return
+	}
+
+	this.any.depth++
+	if value.CanInterface() {
+		this.appendAny(key, value.Interface())
+	} else {
+		this.appendReflect(key, value)
+	}
+	this.any.depth--
+}
+
+// takeElem tells whether the budget has an element left, and takes it.
+func (this *Log) takeElem() bool {
+	if this.any.full || this.any.elems <= 0 || len(this.buf) >= this.any.limit {
+		return false
+	}
+	this.any.elems--
+	return true
+}
+
+// appendElem appends an element, or returns false if it overran the budget.
+func (this *Log) appendElem(key string, value reflect.Value) bool {
+	size := len(this.buf)
+	this.appendNested(key, value)
+	if !this.any.full && len(this.buf) > this.any.limit {
+		this.buf = this.buf[:size]
+		this.any.full = true
+		return false
+	}
+	return true
+}
+
+// enterMarshaler tells whether a value nested by a marshaler may be appended.
+func (this *Log) enterMarshaler(key string, value interface{}) bool {
+	if !this.any.active {
+		return true
+	}
+	if this.any.depth >= maxAnyDepth {
+		this.buf = binary.AppendStringContext(this.buf, key, fmt.Sprintf("%T", value))
+		return false
+	}
+	this.any.depth++
+	return true
+}
+
+func (this *Log) leaveMarshaler() {
+	if this.any.active {
+		this.any.depth--
+	}
+}
+
+func (this *Log) appendStruct(key string, value reflect.Value) {
+	if !this.beginNested(key, binary.AppendObjectBegin) {
+		return
+	}
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if !this.takeElem() || !this.appendElem(field.Name, value.Field(i)) {
+			this.appendMore(moreKey, typ.NumField()-i)
+			break
+		}
+	}
+	this.endNested()
+}
+
+func (this *Log) appendSlice(key string, value reflect.Value) {
+	if !this.beginNested(key, binary.AppendArrayBegin) {
+		return
+	}
+	size := value.Len()
+	for i := 0; i < size; i++ {
+		if !this.takeElem() || !this.appendElem("", value.Index(i)) {
+			this.appendMore("", size-i)
+			break
+		}
+	}
+	this.endNested()
+}
+
+type mapEntry struct {
+	name  string
+	value reflect.Value
+}
+
+// appendMap appends the entries of a map sorted by key, as many as the budget
+// allows.
+func (this *Log) appendMap(key string, value reflect.Value) {
+	size := value.Len()
+	taken := min(size, this.any.elems)
+	entries := make([]mapEntry, 0, taken)
+	for iter := value.MapRange(); len(entries) < taken && iter.Next(); {
+		entries = append(entries, mapEntry{name: mapKeyName(iter.Key()), value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	if !this.beginNested(key, binary.AppendObjectBegin) {
+		return
+	}
+	written := 0
+	for ; written < len(entries); written++ {
+		entry := &entries[written]
+		if !this.takeElem() || !this.appendElem(entry.name, entry.value) {
+			break
+		}
+	}
+	if written < size {
+		this.appendMore(moreKey, size-written)
+	}
+	this.endNested()
+}
+
+func mapKeyName(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return fmt.Sprint(key)
+}
+
+func (this *Log) appendMore(key string, count int) {
+	this.buf = binary.AppendStringContext(this.buf, key, fmt.Sprintf("(%d more)", count))
+}
+
+func reflectBytes(value reflect.Value) []byte {
+	if value.Kind() == reflect.Slice {
+		return value.Bytes()
+	}
+	b := make([]byte, value.Len())
+	reflect.Copy(reflect.ValueOf(b), value)
+	return b
+}
@@ -0,0 +1,252 @@
+package logger
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+)
+
+type testStringer struct {
+	s string
+}
+
+func (this *testStringer) String() string {
+	if this.s == "" {
+		panic("empty")
+	}
+	return this.s
+}
+
+type testText struct{}
+
+func (this *testText) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+type testFaultyObject struct{}
+
+func (this testFaultyObject) MarshalLogObject(encoder *ObjectEncoder) {
+	encoder.Int("partial", 1)
+	panic("half way")
+}
+
+type testNode struct {
+	Name string
+	Next *testNode
+	Kids []*testNode
+}
+
+func logAny(t *testing.T, kvs ...interface{}) *Record {
+	t.Helper()
+
+	logger, dir := newTestLogger(t)
+	log := logger.Info()
+	for i := 0; i < len(kvs); i += 2 {
+		log = log.Any(kvs[i].(string), kvs[i+1])
+	}
+	log.Commit("any")
+
+	records := readRecords(t, dir)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	return records[0]
+}
+
+func countElems(contexts []binary.Context) int {
+	count := len(contexts)
+	for i := range contexts {
+		if kind := contexts[i].Kind; kind == binary.Array || kind == binary.Object {
+			count += countElems(contexts[i].Elems())
+		}
+	}
+	return count
+}
+
+func TestAnyDispatch(t *testing.T) {
+	tm := time.Unix(1e9, 0)
+	record := logAny(t,
+		"int", 3,
+		"str", "s",
+		"bytes", []byte{1, 2},
+		"time", tm,
+		"duration", time.Second,
+		"error", errors.New("e"),
+		"stringer", &testStringer{s: "str"},
+		"text", &testText{},
+		"struct", struct {
+			A int
+			b int
+		}{A: 1, b: 2},
+		"slice", []string{"x", "y"},
+		"map", map[string]int{"b": 2, "a": 1},
+		"nil", nil,
+	)
+
+	kinds := map[string]binary.ValueKind{
+		"int":      binary.Int64,
+		"str":      binary.String,
+		"bytes":    binary.Bytes,
+		"time":     binary.Time,
+		"duration": binary.Duration,
+		"error":    binary.Error,
+		"stringer": binary.String,
+		"text":     binary.String,
+		"struct":   binary.Object,
+		"slice":    binary.Array,
+		"map":      binary.Object,
+		"nil":      binary.String,
+	}
+	for key, kind := range kinds {
+		if context := record.Context(key); context == nil || context.Kind != kind {
+			t.Errorf("%s = %+v, want kind %d", key, context, kind)
+		}
+	}
+	if got := record.Context("stringer").Str(); got != "str" {
+		t.Errorf("stringer = %q", got)
+	}
+	if got := record.Context("text").Str(); got != "text" {
+		t.Errorf("text = %q", got)
+	}
+	if fields := record.Context("struct").Elems(); len(fields) != 1 || fields[0].Key != "A" {
+		t.Errorf("struct fields = %+v, want only the exported one", fields)
+	}
+	entries := record.Context("map").Elems()
+	if len(entries) != 2 || entries[0].Key != "a" || entries[1].Key != "b" {
+		t.Errorf("map entries = %+v, want sorted by key", entries)
+	}
+}
+
+func TestAnyNilPointers(t *testing.T) {
+	var stringer *testStringer
+	record := logAny(t,
+		"url", (*url.URL)(nil),
+		"stringer", stringer,
+		"text", (*testText)(nil),
+		"node", (*testNode)(nil),
+	)
+
+	for _, key := range []string{"url", "stringer", "text", "node"} {
+		context := record.Context(key)
+		if context == nil || context.Kind != binary.String || context.Str() != nilValue {
+			t.Errorf("%s = %+v, want %s", key, context, nilValue)
+		}
+	}
+}
+
+func TestAnyPanickingMethods(t *testing.T) {
+	record := logAny(t,
+		"stringer", &testStringer{},
+		"object", testFaultyObject{},
+		"after", 1,
+	)
+
+	want := map[string]string{
+		"stringer": "%!v(PANIC=String method: empty)",
+		"object":   "%!v(PANIC=MarshalLogObject method: half way)",
+	}
+	for key, msg := range want {
+		if context := record.Context(key); context == nil || context.Kind != binary.String || context.Str() != msg {
+			t.Errorf("%s = %+v, want %q", key, context, msg)
+		}
+	}
+	if record.Context("partial") != nil {
+		t.Error("output of the panicking marshaler kept")
+	}
+	if after := record.Context("after"); after == nil || after.Int() != 1 {
+		t.Errorf("after = %+v", after)
+	}
+}
+
+func TestAnyBudget(t *testing.T) {
+	// 64^4 elements if the limit applied per level.
+	wide := make([]interface{}, 64)
+	for depth := 0; depth < 3; depth++ {
+		level := make([]interface{}, 64)
+		for i := range level {
+			level[i] = wide
+		}
+		wide = level
+	}
+	huge := make(map[int]string, 100000)
+	for i := 0; i < 100000; i++ {
+		huge[i] = strings.Repeat("x", 10)
+	}
+	cycle := &testNode{Name: "loop"}
+	cycle.Next = cycle
+
+	start := time.Now()
+	record := logAny(t, "wide", wide, "huge", huge, "cycle", cycle)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %v", elapsed)
+	}
+
+	for _, key := range []string{"wide", "huge", "cycle"} {
+		context := record.Context(key)
+		if context == nil {
+			t.Fatalf("no context %q", key)
+		}
+		if n := countElems(context.Elems()); n > maxAnyElems+maxAnyDepth+1 {
+			t.Errorf("%s has %d elements, budget is %d", key, n, maxAnyElems)
+		}
+	}
+
+	entries := record.Context("huge").Elems()
+	more := entries[len(entries)-1]
+	if more.Key != moreKey || !strings.HasSuffix(more.Str(), " more)") {
+		t.Errorf("last entry of huge map = %+v, want the count of the rest", more)
+	}
+}
+
+func TestAnyMarshalerDepth(t *testing.T) {
+	record := logAny(t, "deep", testDeep(40))
+
+	context := record.Context("deep")
+	levels := 0
+	for context.Kind == binary.Object {
+		levels++
+		context = &context.Elems()[0]
+	}
+	if levels != maxAnyDepth+1 || context.Str() != "logger.testDeep" {
+		t.Errorf("%d levels ending in %+v, want %d ending in the type", levels, context, maxAnyDepth+1)
+	}
+}
+
+func TestAnyBytes(t *testing.T) {
+	chunk := strings.Repeat("x", 1000)
+	chunks := make([]string, 100)
+	for i := range chunks {
+		chunks[i] = chunk
+	}
+	record := logAny(t,
+		"chunks", chunks,
+		"nested", [][]string{chunks},
+		"string", strings.Repeat("x", maxAnyBytes),
+		"tags", testTags(chunks),
+		"after", 1)
+
+	for _, key := range []string{"chunks", "nested"} {
+		elems := record.Context(key).Elems()
+		if key == "nested" {
+			elems = elems[0].Elems()
+		}
+		if n := len(elems) - 1; n*len(chunk) > maxAnyBytes || n < maxAnyBytes/len(chunk)-1 {
+			t.Errorf("%s has %d chunks within %d bytes", key, n, maxAnyBytes)
+		}
+		if more := elems[len(elems)-1]; !strings.HasSuffix(more.Str(), " more)") {
+			t.Errorf("last element of %s = %+v, want the count of the rest", key, more)
+		}
+	}
+	for _, key := range []string{"string", "tags"} {
+		if context := record.Context(key); context.Kind != binary.String || context.Str() != largeValue {
+			t.Errorf("%s = %+v, want %q", key, context, largeValue)
+		}
+	}
+	if after := record.Context("after"); after == nil || after.Int() != 1 {
+		t.Errorf("after = %+v", after)
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type callSite struct {
+	count uint64
+	last  int64
+}
+
+var callSites sync.Map
+
+func (this *Logger) If(cond bool) *Logger {
+	if cond {
+		return this
+	}
+	return nil
+}
+
+func (this *Logger) EveryN(n int) *Logger {
+	if n <= 0 {
+		panic("glog: every n: n must be positive")
+	}
+
+	site := getCallSite(this.frameSkip() + 1)
+	count := atomic.AddUint64(&site.count, 1)
+	return this.If((count-1)%uint64(n) == 0)
+}
+
+func (this *Logger) FirstN(n int) *Logger {
+	if n < 0 {
+		panic("glog: first n: n must not be negative")
+	}
+
+	site := getCallSite(this.frameSkip() + 1)
+	if atomic.LoadUint64(&site.count) >= uint64(n) {
+		return nil
+	}
+	count := atomic.AddUint64(&site.count, 1)
+	return this.If(count <= uint64(n))
+}
+
+func (this *Logger) Every(interval time.Duration) *Logger {
+	site := getCallSite(this.frameSkip() + 1)
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&site.last)
+	if last != 0 && now-last < int64(interval) {
+		return nil
+	}
+	return this.If(atomic.CompareAndSwapInt64(&site.last, last, now))
+}
+
+func getCallSite(frameSkip int) *callSite {
+	var pcs [1]uintptr
+	runtime.Callers(frameSkip+2, pcs[:])
+
+	if site, ok := callSites.Load(pcs[0]); ok {
+		return site.(*callSite)
+	}
+	site, _ := callSites.LoadOrStore(pcs[0], new(callSite))
+	return site.(*callSite)
+}
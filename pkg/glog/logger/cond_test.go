@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+// resetCallSites forgets the state of all call sites, which would otherwise
+// carry over when a test is run again.
+func resetCallSites() {
+	callSites.Range(func(key, _ interface{}) bool {
+		callSites.Delete(key)
+		return true
+	})
+}
+
+func TestCond(t *testing.T) {
+	resetCallSites()
+	logger, dir := newTestLogger(t)
+	counts := make(map[string]int)
+	count := func(name string, logger *Logger) {
+		if logger != nil {
+			counts[name]++
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		count("if", logger.If(i == 5))
+		count("every 3", logger.EveryN(3))
+		count("every 3 elsewhere", logger.EveryN(3))
+		count("first 2", logger.FirstN(2))
+		count("first 0", logger.FirstN(0))
+		count("every hour", logger.Every(time.Hour))
+		logger.EveryN(5).Info().Commit("every 5")
+	}
+
+	want := map[string]int{
+		"if":                1,
+		"every 3":           4,
+		"every 3 elsewhere": 4,
+		"first 2":           2,
+		"every hour":        1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("%s passed %d times, want %d", name, counts[name], n)
+		}
+	}
+	if counts["first 0"] != 0 {
+		t.Errorf("first 0 passed %d times", counts["first 0"])
+	}
+	if records := readRecords(t, dir); len(records) != 2 {
+		t.Errorf("got %d records, want 2", len(records))
+	}
+}
+
+func TestCondEvery(t *testing.T) {
+	resetCallSites()
+	logger, _ := newTestLogger(t)
+	passed := 0
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if logger.Every(20*time.Millisecond) != nil {
+			passed++
+		}
+	}
+	if passed == 0 || passed > 3 {
+		t.Errorf("passed %d times in 50ms at most every 20ms", passed)
+	}
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"github.com/gratonos/glog/internal/encoding/binary"
+	ilog "github.com/gratonos/glog/internal/logger"
+)
+
+type (
+	Record  = binary.Record
+	Context = binary.Context
+	Hook    = ilog.Hook
+)
+
+func (this *Logger) AddHook(name string, hook Hook) error {
+	return this.logger.AddHook(name, hook)
+}
+
+func (this *Logger) RemoveHook(name string) bool {
+	return this.logger.RemoveHook(name)
+}
+
+func (this *Logger) Hooks() []string {
+	return this.logger.Hooks()
+}
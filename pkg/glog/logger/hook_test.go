@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestHookRewrite(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	err := logger.AddHook("host", func(record *Record) bool {
+		record.Msg = strings.ToUpper(record.Msg)
+		return record.AddContext("host", "box") == nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info().Int("n", 1).Commit("hello")
+
+	records := readRecords(t, dir)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	record := records[0]
+	if record.Msg != "HELLO" {
+		t.Errorf("msg = %q, want %q", record.Msg, "HELLO")
+	}
+	if context := record.Context("n"); context == nil || context.Int() != 1 {
+		t.Errorf("context n = %v, want 1", context)
+	}
+	if context := record.Context("host"); context == nil || context.Str() != "box" {
+		t.Errorf("context host = %v, want box", context)
+	}
+}
+
+func TestHookDrop(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	seen := 0
+	logger.AddHook("health", func(record *Record) bool {
+		seen++
+		return record.Msg != "health"
+	})
+
+	logger.Info().Commit("a")
+	logger.Info().Commit("health")
+	logger.Info().Commit("b")
+
+	if seen != 3 {
+		t.Errorf("hook saw %d records, want 3", seen)
+	}
+	records := readRecords(t, dir)
+	if got := msgs(records); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("msgs = %q, want [a b]", got)
+	}
+	// Dropped records are not numbered.
+	for i, record := range records {
+		if record.Seq != uint64(i+1) {
+			t.Errorf("seq of %q = %d, want %d", record.Msg, record.Seq, i+1)
+		}
+	}
+}
+
+func TestHookOrder(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	appender := func(s string) Hook {
+		return func(record *Record) bool {
+			record.Msg += s
+			return true
+		}
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if err := logger.AddHook(name, appender(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.AddHook("a", appender("x")); err == nil {
+		t.Error("adding a hook with an existing name succeeded")
+	}
+
+	logger.Info().Commit("")
+	if !logger.RemoveHook("b") {
+		t.Fatal("hook b not removed")
+	}
+	if logger.RemoveHook("b") {
+		t.Error("hook b removed twice")
+	}
+	logger.Info().Commit("")
+	logger.AddHook("b", appender("b"))
+	logger.Info().Commit("")
+
+	if got := logger.Hooks(); !reflect.DeepEqual(got, []string{"a", "c", "b"}) {
+		t.Errorf("hooks = %q, want [a c b]", got)
+	}
+	if got := msgs(readRecords(t, dir)); !reflect.DeepEqual(got, []string{"abc", "ac", "acb"}) {
+		t.Errorf("msgs = %q, want [abc ac acb]", got)
+	}
+}
+
+func TestHookLogging(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	logger.AddHook("echo", func(record *Record) bool {
+		if record.Msg != "echo" {
+			logger.Info().Commit("echo")
+		}
+		return true
+	})
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info().Commit("hello")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging from a hook deadlocked")
+	}
+
+	if got := msgs(readRecords(t, dir)); !reflect.DeepEqual(got, []string{"echo", "hello"}) {
+		t.Errorf("msgs = %q, want [echo hello]", got)
+	}
+}
+
+func TestHookPanic(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	var handled []error
+	logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.FileWriter.ErrorHandler = func(_ time.Time, err error) {
+			handled = append(handled, err)
+		}
+		return config
+	})
+	logger.AddHook("bad", func(record *Record) bool {
+		panic(errors.New("boom"))
+	})
+	logger.AddHook("mark", func(record *Record) bool {
+		record.Msg += "!"
+		return true
+	})
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info().Commit("a")
+		logger.RemoveHook("bad")
+		logger.Info().Commit("b")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logger locked after a hook panicked")
+	}
+
+	if got := msgs(readRecords(t, dir)); !reflect.DeepEqual(got, []string{"a!", "b!"}) {
+		t.Errorf("msgs = %q, want [a! b!]", got)
+	}
+	if len(handled) != 1 || !strings.Contains(handled[0].Error(), "hook 'bad' panicked: boom") {
+		t.Errorf("handled errors = %v", handled)
+	}
+}
+
+func TestHookIllegalRecord(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	var handled []error
+	logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.FileWriter.ErrorHandler = func(_ time.Time, err error) {
+			handled = append(handled, err)
+		}
+		return config
+	})
+	logger.AddHook("illegal", func(record *Record) bool {
+		switch record.Msg {
+		case "level":
+			record.Level = iface.Level(100)
+		case "kind":
+			record.Contexts = append(record.Contexts, Context{Key: "k", Kind: 100})
+		}
+		return true
+	})
+
+	for _, msg := range []string{"a", "level", "kind", "b"} {
+		logger.Info().Commit(msg)
+	}
+
+	records := readRecords(t, dir)
+	if got := msgs(records); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("msgs = %q, want [a b]", got)
+	}
+	if records[1].Seq != 2 {
+		t.Errorf("seq of b = %d, want 2", records[1].Seq)
+	}
+	if len(handled) != 2 {
+		t.Errorf("handled errors = %v, want one for each illegal record", handled)
+	}
+}
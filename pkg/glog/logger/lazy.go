@@ -0,0 +1,85 @@
+package logger
+
+func (this *Log) Lazy(key string, fn func() interface{}) *Log {
+	if this != nil && fn != nil {
+		this.appendAnyValue(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyBool(key string, fn func() bool) *Log {
+	if this != nil && fn != nil {
+		this.Bool(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyInt(key string, fn func() int) *Log {
+	if this != nil && fn != nil {
+		this.Int(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyInt64(key string, fn func() int64) *Log {
+	if this != nil && fn != nil {
+		this.Int64(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyUint(key string, fn func() uint) *Log {
+	if this != nil && fn != nil {
+		this.Uint(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyUint64(key string, fn func() uint64) *Log {
+	if this != nil && fn != nil {
+		this.Uint64(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyFloat64(key string, fn func() float64) *Log {
+	if this != nil && fn != nil {
+		this.Float64(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyStr(key string, fn func() string) *Log {
+	if this != nil && fn != nil {
+		this.Str(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyBytes(key string, fn func() []byte) *Log {
+	if this != nil && fn != nil {
+		this.Bytes(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyErr(key string, fn func() error) *Log {
+	if this != nil && fn != nil {
+		this.NamedErr(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyObject(key string, fn func() LogObjectMarshaler) *Log {
+	if this != nil && fn != nil {
+		this.Object(key, fn())
+	}
+	return this
+}
+
+func (this *Log) LazyArray(key string, fn func() LogArrayMarshaler) *Log {
+	if this != nil && fn != nil {
+		this.Array(key, fn())
+	}
+	return this
+}
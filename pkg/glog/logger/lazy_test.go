@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestLazy(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Level = iface.Info
+		return config
+	})
+
+	calls := 0
+	value := func() interface{} {
+		calls++
+		return []int{1, 2}
+	}
+	str := func() string {
+		calls++
+		return "s"
+	}
+
+	logger.Debug().Lazy("skipped", value).LazyStr("skipped too", str).Commit("debug")
+	if calls != 0 {
+		t.Fatalf("lazy fields of a disabled log evaluated %d times", calls)
+	}
+	logger.Info().Lazy("list", value).LazyStr("str", str).Lazy("nil func", nil).Commit("info")
+	if calls != 2 {
+		t.Fatalf("lazy fields of an enabled log evaluated %d times, want 2", calls)
+	}
+
+	records := readRecords(t, dir)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if list := records[0].Context("list"); list == nil || len(list.Elems()) != 2 {
+		t.Errorf("list = %+v", list)
+	}
+	if str := records[0].Context("str"); str == nil || str.Str() != "s" {
+		t.Errorf("str = %+v", str)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	logger, _ := newTestLogger(t)
+	logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Level = iface.Warn
+		return config
+	})
+
+	for level, want := range map[iface.Level]bool{
+		iface.Info:  false,
+		iface.Warn:  true,
+		iface.Fatal: true,
+		iface.Off:   false,
+	} {
+		if got := logger.Enabled(level); got != want {
+			t.Errorf("Enabled(%v) = %v, want %v", level, got, want)
+		}
+	}
+	if (*Logger)(nil).Enabled(iface.Fatal) {
+		t.Error("nil logger enabled")
+	}
+}
@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"fmt"
+	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,11 +15,18 @@ import (
 )
 
 type Log struct {
-	logger *ilog.Logger
-	buf    []byte
+	logger  *ilog.Logger
+	buf     []byte
+	stacked bool
+	nesting int
+
+	any anyState
 }
 
-const logBufLen = 1024
+const (
+	logBufLen     = 1024
+	maxStackDepth = 64
+)
 
 var logPool = sync.Pool{
 	New: func() interface{} {
@@ -28,14 +38,17 @@ var logPool = sync.Pool{
 	},
 }
 
-func genLog(logger *ilog.Logger, level iface.Level, pkg string, frameSkip int) *Log {
+func genLog(logger *ilog.Logger, level iface.Level, pkg string, verbosity, frameSkip int) *Log {
 	if logger.Level() > level {
 		return nil
 	}
 
 	log := logPool.Get().(*Log)
 	log.reset(logger)
-	log.appendPreInfo(level, pkg, frameSkip+1)
+	log.appendPreInfo(level, pkg, verbosity, frameSkip+1)
+	if level >= logger.StackLevel() {
+		log.appendStack(frameSkip + 1)
+	}
 	return log
 }
 
@@ -166,12 +179,26 @@ func (this *Log) Str(key, value string) *Log {
 	return this
 }
 
+func (this *Log) Bytes(key string, value []byte) *Log {
+	if this != nil {
+		this.buf = binary.AppendBytesContext(this.buf, key, value)
+	}
+	return this
+}
+
 func (this *Log) Func(fn string) *Log {
 	return this.Str("func", fn)
 }
 
 func (this *Log) Err(err error) *Log {
-	return this.Str("error", err.Error())
+	return this.NamedErr("error", err)
+}
+
+func (this *Log) NamedErr(key string, err error) *Log {
+	if this != nil {
+		this.buf = binary.AppendErrorContext(this.buf, key, err)
+	}
+	return this
 }
 
 func (this *Log) Time(key string, value time.Time) *Log {
@@ -188,6 +215,48 @@ func (this *Log) Duration(key string, value time.Duration) *Log {
 	return this
 }
 
+func (this *Log) Fmt(format string) *Log {
+	if this != nil {
+		this.buf = binary.AppendFormat(this.buf, format)
+	}
+	return this
+}
+
+func (this *Log) IntFmt(key string, value int, format string) *Log {
+	return this.Fmt(format).Int(key, value)
+}
+
+func (this *Log) Int64Fmt(key string, value int64, format string) *Log {
+	return this.Fmt(format).Int64(key, value)
+}
+
+func (this *Log) UintFmt(key string, value uint, format string) *Log {
+	return this.Fmt(format).Uint(key, value)
+}
+
+func (this *Log) Uint64Fmt(key string, value uint64, format string) *Log {
+	return this.Fmt(format).Uint64(key, value)
+}
+
+func (this *Log) Float64Fmt(key string, value float64, format string) *Log {
+	return this.Fmt(format).Float64(key, value)
+}
+
+func (this *Log) StrFmt(key, value, format string) *Log {
+	return this.Fmt(format).Str(key, value)
+}
+
+func (this *Log) TimeFmt(key string, value time.Time, layout string) *Log {
+	return this.Fmt(layout).Time(key, value)
+}
+
+func (this *Log) Stack() *Log {
+	if this != nil {
+		this.appendStack(0 + 1)
+	}
+	return this
+}
+
 func (this *Log) Mark() *Log {
 	if this != nil {
 		this.buf = binary.AppendMark(this.buf)
@@ -202,23 +271,58 @@ func (this *Log) Commit(msg string) {
 	}
 }
 
+// Commitf formats the message only if the log is to be committed.
+func (this *Log) Commitf(template string, args ...interface{}) {
+	if this != nil {
+		if !this.logger.Committing() {
+			this.put()
+			return
+		}
+		this.buf = binary.AppendTemplate(this.buf, template)
+		this.buf = binary.AppendMsg(this.buf, fmt.Sprintf(template, args...))
+		this.logger.Commit(this.emit, this.put)
+	}
+}
+
 func (this *Log) reset(logger *ilog.Logger) {
 	this.logger = logger
 	this.buf = binary.ResetBuf(this.buf)
+	this.stacked = false
 }
 
-func (this *Log) appendPreInfo(level iface.Level, pkg string, frameSkip int) {
+func (this *Log) appendPreInfo(level iface.Level, pkg string, verbosity, frameSkip int) {
 	this.buf = binary.AppendLevel(this.buf, level)
+	if verbosity > 0 {
+		this.buf = binary.AppendVerbosity(this.buf, verbosity)
+	}
 	this.buf = binary.AppendPkg(this.buf, pkg)
-	if this.logger.FileLine() {
-		file, line := fileAndLine(frameSkip + 1)
+
+	fileLine, funcName := this.logger.FileLine(), this.logger.FuncName()
+	if !fileLine && !funcName {
+		return
+	}
+
+	file, line, fn := caller(frameSkip+1, fileLine && this.logger.FullPath())
+	if fileLine {
 		this.buf = binary.AppendFile(this.buf, file)
 		this.buf = binary.AppendLine(this.buf, line)
 	}
+	if funcName {
+		this.buf = binary.AppendFunc(this.buf, fn)
+	}
 }
 
-func (this *Log) emit(tm time.Time) []byte {
+func (this *Log) appendStack(frameSkip int) {
+	if this.stacked {
+		return
+	}
+	this.buf = binary.AppendStack(this.buf, callStack(frameSkip+1))
+	this.stacked = true
+}
+
+func (this *Log) emit(tm time.Time, seq uint64) []byte {
 	this.buf = binary.AppendTime(this.buf, tm)
+	this.buf = binary.AppendSeq(this.buf, seq)
 	this.buf = binary.AppendEnd(this.buf)
 	return this.buf
 }
@@ -227,11 +331,77 @@ func (this *Log) put() {
 	logPool.Put(this)
 }
 
-func fileAndLine(frameSkip int) (string, int) {
-	_, file, line, ok := runtime.Caller(frameSkip + 1)
-	if ok {
-		return filepath.Base(file), line
-	} else {
-		return "???", 0
+// callerFrame is where a log is committed, as resolved from its PC.
+type callerFrame struct {
+	pkg      string
+	file     string
+	fullPath string
+	line     int
+	fn       string
+}
+
+// callerFrames caches the callerFrame of each PC, since resolving a PC with
+// runtime.CallersFrames allocates.
+var callerFrames sync.Map
+
+func caller(frameSkip int, fullPath bool) (string, int, string) {
+	var pcs [1]uintptr
+	if runtime.Callers(frameSkip+2, pcs[:]) == 0 {
+		return "???", 0, "???"
+	}
+	frame := lookupCaller(pcs[0])
+	if fullPath {
+		return frame.fullPath, frame.line, frame.fn
+	}
+	return frame.file, frame.line, frame.fn
+}
+
+// CallerPkg returns the package of the caller frameSkip frames up.
+func CallerPkg(frameSkip int) string {
+	var pcs [1]uintptr
+	if runtime.Callers(frameSkip+2, pcs[:]) == 0 {
+		return "???"
 	}
+	return lookupCaller(pcs[0]).pkg
+}
+
+func lookupCaller(pc uintptr) *callerFrame {
+	if frame, ok := callerFrames.Load(pc); ok {
+		return frame.(*callerFrame)
+	}
+
+	// Unlike runtime.FuncForPC, CallersFrames tells the function a call was
+	// inlined into apart from the inlined one.
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	frame := &callerFrame{pkg: "???", file: "???", fullPath: "???", fn: "???"}
+	if f.Function != "" {
+		pkg, fn := splitFuncName(f.Function)
+		if pkg != "" {
+			frame.pkg = pkg
+		}
+		frame.file, frame.line, frame.fn = filepath.Base(f.File), f.Line, fn
+		if pkg == "" || pkg == "main" {
+			frame.fullPath = path.Join(filepath.Base(filepath.Dir(f.File)), frame.file)
+		} else {
+			frame.fullPath = path.Join(pkg, frame.file)
+		}
+	}
+	cached, _ := callerFrames.LoadOrStore(pc, frame)
+	return cached.(*callerFrame)
+}
+
+func splitFuncName(name string) (string, string) {
+	lastSlash := strings.LastIndexByte(name, '/')
+	nextDot := strings.IndexByte(name[lastSlash+1:], '.')
+	if nextDot < 0 {
+		return "", name
+	}
+	dot := lastSlash + 1 + nextDot
+	return name[:dot], name[dot+1:]
+}
+
+func callStack(frameSkip int) []binary.Frame {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(frameSkip+2, pcs[:])
+	return binary.StackFrames(pcs[:n])
 }
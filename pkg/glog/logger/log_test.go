@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/text"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestNamedErr(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	var typedNil *nilErr
+	logger.Error().
+		Err(errors.New("first")).
+		NamedErr("cause", errors.New("second")).
+		NamedErr("nil", nil).
+		NamedErr("typed nil", typedNil).
+		Commit("failed")
+
+	records := readRecords(t, dir)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	want := map[string]string{
+		"error":     "first",
+		"cause":     "second",
+		"nil":       "<nil>",
+		"typed nil": "<nil>",
+	}
+	for key, msg := range want {
+		context := records[0].Context(key)
+		if context == nil {
+			t.Errorf("no context %q", key)
+		} else if got := context.Err().Msg; got != msg {
+			t.Errorf("%s = %q, want %q", key, got, msg)
+		}
+	}
+}
+
+type nilErr struct{}
+
+func (this *nilErr) Error() string {
+	panic("nil receiver")
+}
+
+func TestFmt(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	logger.Info().
+		IntFmt("mask", 5, "%08b").
+		TimeFmt("day", tm, "2006-01-02").
+		Int("plain", 5).
+		Commit("formatted")
+
+	records := readRecords(t, dir)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	formats := map[string]string{"mask": "%08b", "day": "2006-01-02", "plain": ""}
+	for key, format := range formats {
+		if context := records[0].Context(key); context == nil || context.Format != format {
+			t.Errorf("format of %s = %+v, want %q", key, context, format)
+		}
+	}
+
+	line := string(text.FormatRecord(records[0], iface.TextConfig{}))
+	for _, want := range []string{"(mask: 00000101)", "(day: 2024-05-06)", "(plain: 5)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("%q does not contain %q", line, want)
+		}
+	}
+}
+
+type loggingStringer struct {
+	logger *Logger
+	calls  int
+}
+
+func (this *loggingStringer) String() string {
+	this.calls++
+	this.logger.Info().Commit("from String")
+	return "arg"
+}
+
+func TestCommitf(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	arg := &loggingStringer{logger: logger}
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info().Commitf("got %s and %d", arg, 3)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("formatting an argument that logs deadlocked")
+	}
+
+	records := readRecords(t, dir)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	record := records[1]
+	if record.Msg != "got arg and 3" || record.Template != "got %s and %d" {
+		t.Errorf("msg = %q, template = %q", record.Msg, record.Template)
+	}
+}
+
+func TestCommitfSkipped(t *testing.T) {
+	logger, _ := newTestLogger(t)
+	logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.FileWriter.Enable = false
+		return config
+	})
+	arg := &loggingStringer{logger: logger}
+
+	logger.Info().Commitf("%s", arg)
+	if arg.calls != 0 {
+		t.Errorf("arguments formatted for a log that nothing sees")
+	}
+
+	logger.AddHook("count", func(record *Record) bool { return true })
+	logger.Info().Commitf("%s", arg)
+	if arg.calls != 1 {
+		t.Errorf("arguments formatted %d times for a hooked log, want 1", arg.calls)
+	}
+}
+
+func TestCaller(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	logger.Info().Commit("default")
+	_, _, line, _ := runtime.Caller(0)
+	line--
+	err := logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.FileLine = true
+		config.FullPath = true
+		config.FuncName = true
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info().Commit("full")
+
+	records := readRecords(t, dir)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if record := records[0]; record.File != "log_test.go" || record.Line != line || record.Func != "" {
+		t.Errorf("default caller = %s:%d %q", record.File, record.Line, record.Func)
+	}
+	const pkg = "github.com/gratonos/glog/pkg/glog/logger"
+	if record := records[1]; record.File != pkg+"/log_test.go" || record.Func != "TestCaller" {
+		t.Errorf("full caller = %s %q", record.File, record.Func)
+	}
+}
+
+func TestSplitFuncName(t *testing.T) {
+	tests := []struct {
+		name, pkg, fn string
+	}{
+		{"main.main", "main", "main"},
+		{"github.com/a/b.(*T).M", "github.com/a/b", "(*T).M"},
+		{"github.com/a/b.F.func1", "github.com/a/b", "F.func1"},
+		{"F", "", "F"},
+	}
+	for _, test := range tests {
+		if pkg, fn := splitFuncName(test.name); pkg != test.pkg || fn != test.fn {
+			t.Errorf("splitFuncName(%q) = %q, %q", test.name, pkg, fn)
+		}
+	}
+}
@@ -8,8 +8,10 @@ import (
 )
 
 type Logger struct {
-	logger *ilog.Logger
-	pkg    string
+	logger     *ilog.Logger
+	pkg        string
+	verbosity  int
+	callerSkip int
 }
 
 func NewLogger(logger *ilog.Logger, pkg string) *Logger {
@@ -19,35 +21,66 @@ func NewLogger(logger *ilog.Logger, pkg string) *Logger {
 	}
 }
 
+// NewLog begins a log of logger on behalf of package pkg.
+func NewLog(logger *ilog.Logger, level iface.Level, pkg string, frameSkip int) *Log {
+	return genLog(logger, level, pkg, 0, frameSkip+1)
+}
+
 func (this *Logger) Log(level iface.Level, frameSkip int) *Log {
 	if !level.LegalForLog() {
 		panic(fmt.Sprintf("glog: illegal log level: %d", level))
 	}
-	return genLog(this.logger, level, this.pkg, frameSkip+1)
+	return this.genLog(level, frameSkip+1)
+}
+
+func (this *Logger) Enabled(level iface.Level) bool {
+	return this != nil && level.LegalForLog() && this.logger.Level() <= level
+}
+
+func (this *Logger) WithCallerSkip(skip int) *Logger {
+	if this == nil {
+		return nil
+	}
+	logger := *this
+	logger.callerSkip += skip
+	return &logger
+}
+
+func (this *Logger) V(verbosity int) *Logger {
+	if this == nil || this.logger.Verbosity(this.pkg) < verbosity {
+		return nil
+	}
+	if verbosity <= 0 || verbosity == this.verbosity {
+		return this
+	}
+
+	logger := *this
+	logger.verbosity = verbosity
+	return &logger
 }
 
 func (this *Logger) Trace() *Log {
-	return genLog(this.logger, iface.Trace, this.pkg, 0+1)
+	return this.genLog(iface.Trace, 0+1)
 }
 
 func (this *Logger) Debug() *Log {
-	return genLog(this.logger, iface.Debug, this.pkg, 0+1)
+	return this.genLog(iface.Debug, 0+1)
 }
 
 func (this *Logger) Info() *Log {
-	return genLog(this.logger, iface.Info, this.pkg, 0+1)
+	return this.genLog(iface.Info, 0+1)
 }
 
 func (this *Logger) Warn() *Log {
-	return genLog(this.logger, iface.Warn, this.pkg, 0+1)
+	return this.genLog(iface.Warn, 0+1)
 }
 
 func (this *Logger) Error() *Log {
-	return genLog(this.logger, iface.Error, this.pkg, 0+1)
+	return this.genLog(iface.Error, 0+1)
 }
 
 func (this *Logger) Fatal() *Log {
-	return genLog(this.logger, iface.Fatal, this.pkg, 0+1)
+	return this.genLog(iface.Fatal, 0+1)
 }
 
 func (this *Logger) Config() iface.Logger {
@@ -61,3 +94,17 @@ func (this *Logger) SetConfig(config iface.Logger) error {
 func (this *Logger) UpdateConfig(updater func(config iface.Logger) iface.Logger) error {
 	return this.logger.UpdateConfig(updater)
 }
+
+func (this *Logger) genLog(level iface.Level, frameSkip int) *Log {
+	if this == nil {
+		return nil
+	}
+	return genLog(this.logger, level, this.pkg, this.verbosity, this.callerSkip+frameSkip+1)
+}
+
+func (this *Logger) frameSkip() int {
+	if this == nil {
+		return 0
+	}
+	return this.callerSkip
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	ilog "github.com/gratonos/glog/internal/logger"
+	"github.com/gratonos/glog/internal/writers/file"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// newTestLogger creates a logger that writes binary log files into a
+// temporary directory, which is returned along with it.
+func newTestLogger(t testing.TB) (*Logger, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	logger := ilog.New("test")
+	config := logger.Config()
+	config.ConsoleWriter.Enable = false
+	config.FileWriter.Enable = true
+	config.FileWriter.Format = iface.Binary
+	config.FileWriter.Dir = dir
+	if err := logger.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.Close() })
+	return NewLogger(logger, "test"), dir
+}
+
+// readRecords reads the records written into dir. Each record is decoded
+// from a copy of its own, since a record read is only valid until the next
+// read.
+func readRecords(t testing.TB, dir string) []*Record {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*"+file.Extensions[iface.Binary]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []*Record
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader := binary.NewBytesReader(data)
+		var record Record
+		for {
+			err := reader.Read(&record)
+			if err == binary.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read %s: %v", path, err)
+			}
+			copied := new(Record)
+			if _, err := binary.DecodeRecord(copied, binary.AppendRecord(nil, &record)); err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, copied)
+		}
+	}
+	return records
+}
+
+func msgs(records []*Record) []string {
+	var msgs []string
+	for _, record := range records {
+		msgs = append(msgs, record.Msg)
+	}
+	return msgs
+}
+
+func TestV(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	other := NewLogger(logger.logger, "github.com/a/b")
+	err := logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Verbosity = 1
+		config.VModules = []iface.VModule{{Pattern: "github.com/a/...", Verbosity: 3}}
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logger.V(2) != nil {
+		t.Error("V(2) enabled at verbosity 1")
+	}
+	logger.V(0).Info().Commit("v0")
+	logger.V(1).Info().Commit("v1")
+	other.V(3).Info().Commit("v3")
+	if other.V(4) != nil {
+		t.Error("V(4) enabled at verbosity 3")
+	}
+
+	records := readRecords(t, dir)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	for i, want := range []int{0, 1, 3} {
+		if records[i].Verbosity != want {
+			t.Errorf("verbosity of %s = %d, want %d", records[i].Msg, records[i].Verbosity, want)
+		}
+	}
+
+	err = logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.VModules = []iface.VModule{{Pattern: "[", Verbosity: 1}}
+		return config
+	})
+	if err == nil {
+		t.Error("illegal vmodule accepted")
+	}
+}
+
+// logWrapped logs like a wrapper library would, reporting its caller.
+func logWrapped(logger *Logger, msg string) {
+	logger.WithCallerSkip(1).Info().Commit(msg)
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	err := logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.FuncName = true
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logWrapped(logger, "wrapped")
+	logger.Info().Commit("direct")
+	if (*Logger)(nil).WithCallerSkip(1) != nil {
+		t.Error("WithCallerSkip on nil returned a logger")
+	}
+
+	records := readRecords(t, dir)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	for _, record := range records {
+		if record.Func != "TestWithCallerSkip" {
+			t.Errorf("func of %s = %q, want TestWithCallerSkip", record.Msg, record.Func)
+		}
+	}
+}
+
+func TestSeq(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	child := NewLogger(ilog.NewChild("test.child", logger.logger), "test")
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			logger.Info().Commit("parent")
+		} else {
+			child.Info().Commit("child")
+		}
+	}
+
+	// Logs committed to a child are numbered along with those of the logger
+	// whose writers they use.
+	records := readRecords(t, dir)
+	if len(records) != 100 {
+		t.Fatalf("got %d records, want 100", len(records))
+	}
+	for i, record := range records {
+		if record.Seq != uint64(i+1) {
+			t.Fatalf("seq of record %d = %d", i, record.Seq)
+		}
+	}
+}
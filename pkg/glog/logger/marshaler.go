@@ -0,0 +1,339 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+)
+
+type LogObjectMarshaler interface {
+	MarshalLogObject(encoder *ObjectEncoder)
+}
+
+type LogArrayMarshaler interface {
+	MarshalLogArray(encoder *ArrayEncoder)
+}
+
+const tooDeepValue = "<too deep>"
+
+type ObjectEncoder Log
+
+type ArrayEncoder Log
+
+func (this *Log) Object(key string, value LogObjectMarshaler) *Log {
+	if this != nil {
+		this.appendObject(key, value)
+	}
+	return this
+}
+
+func (this *Log) Array(key string, value LogArrayMarshaler) *Log {
+	if this != nil {
+		this.appendArray(key, value)
+	}
+	return this
+}
+
+func (this *Log) appendObject(key string, value LogObjectMarshaler) {
+	if !this.beginNested(key, binary.AppendObjectBegin) {
+		return
+	}
+	if value != nil {
+		value.MarshalLogObject((*ObjectEncoder)(this))
+	}
+	this.endNested()
+}
+
+func (this *Log) appendArray(key string, value LogArrayMarshaler) {
+	if !this.beginNested(key, binary.AppendArrayBegin) {
+		return
+	}
+	if value != nil {
+		value.MarshalLogArray((*ArrayEncoder)(this))
+	}
+	this.endNested()
+}
+
+// beginNested begins an object or array, or appends a placeholder if too deep.
+func (this *Log) beginNested(key string, begin func([]byte, string) []byte) bool {
+	if this.nesting >= binary.MaxNestingDepth {
+		this.buf = binary.AppendStringContext(this.buf, key, tooDeepValue)
+		return false
+	}
+	this.nesting++
+	this.buf = begin(this.buf, key)
+	return true
+}
+
+func (this *Log) endNested() {
+	this.buf = binary.AppendNestedEnd(this.buf)
+	this.nesting--
+}
+
+func (this *ObjectEncoder) Fmt(format string) *ObjectEncoder {
+	this.buf = binary.AppendFormat(this.buf, format)
+	return this
+}
+
+func (this *ObjectEncoder) Bool(key string, value bool) *ObjectEncoder {
+	this.buf = binary.AppendBoolContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Byte(key string, value byte) *ObjectEncoder {
+	this.buf = binary.AppendByteContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Rune(key string, value rune) *ObjectEncoder {
+	this.buf = binary.AppendRuneContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Int(key string, value int) *ObjectEncoder {
+	return this.Int64(key, int64(value))
+}
+
+func (this *ObjectEncoder) Int8(key string, value int8) *ObjectEncoder {
+	this.buf = binary.AppendInt8Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Int16(key string, value int16) *ObjectEncoder {
+	this.buf = binary.AppendInt16Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Int32(key string, value int32) *ObjectEncoder {
+	this.buf = binary.AppendInt32Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Int64(key string, value int64) *ObjectEncoder {
+	this.buf = binary.AppendInt64Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Uint(key string, value uint) *ObjectEncoder {
+	return this.Uint64(key, uint64(value))
+}
+
+func (this *ObjectEncoder) Uint8(key string, value uint8) *ObjectEncoder {
+	this.buf = binary.AppendUint8Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Uint16(key string, value uint16) *ObjectEncoder {
+	this.buf = binary.AppendUint16Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Uint32(key string, value uint32) *ObjectEncoder {
+	this.buf = binary.AppendUint32Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Uint64(key string, value uint64) *ObjectEncoder {
+	this.buf = binary.AppendUint64Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Uintptr(key string, value uintptr) *ObjectEncoder {
+	this.buf = binary.AppendUintptrContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Float32(key string, value float32) *ObjectEncoder {
+	this.buf = binary.AppendFloat32Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Float64(key string, value float64) *ObjectEncoder {
+	this.buf = binary.AppendFloat64Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Complex64(key string, value complex64) *ObjectEncoder {
+	this.buf = binary.AppendComplex64Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Complex128(key string, value complex128) *ObjectEncoder {
+	this.buf = binary.AppendComplex128Context(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Str(key, value string) *ObjectEncoder {
+	this.buf = binary.AppendStringContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Bytes(key string, value []byte) *ObjectEncoder {
+	this.buf = binary.AppendBytesContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Err(key string, err error) *ObjectEncoder {
+	this.buf = binary.AppendErrorContext(this.buf, key, err)
+	return this
+}
+
+func (this *ObjectEncoder) Time(key string, value time.Time) *ObjectEncoder {
+	this.buf = binary.AppendTimeContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Duration(key string, value time.Duration) *ObjectEncoder {
+	this.buf = binary.AppendDurationContext(this.buf, key, value)
+	return this
+}
+
+func (this *ObjectEncoder) Object(key string, value LogObjectMarshaler) *ObjectEncoder {
+	if log := (*Log)(this); log.enterMarshaler(key, value) {
+		log.appendObject(key, value)
+		log.leaveMarshaler()
+	}
+	return this
+}
+
+func (this *ObjectEncoder) Array(key string, value LogArrayMarshaler) *ObjectEncoder {
+	if log := (*Log)(this); log.enterMarshaler(key, value) {
+		log.appendArray(key, value)
+		log.leaveMarshaler()
+	}
+	return this
+}
+
+func (this *ArrayEncoder) Fmt(format string) *ArrayEncoder {
+	this.buf = binary.AppendFormat(this.buf, format)
+	return this
+}
+
+func (this *ArrayEncoder) Bool(value bool) *ArrayEncoder {
+	this.buf = binary.AppendBoolContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Byte(value byte) *ArrayEncoder {
+	this.buf = binary.AppendByteContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Rune(value rune) *ArrayEncoder {
+	this.buf = binary.AppendRuneContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Int(value int) *ArrayEncoder {
+	return this.Int64(int64(value))
+}
+
+func (this *ArrayEncoder) Int8(value int8) *ArrayEncoder {
+	this.buf = binary.AppendInt8Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Int16(value int16) *ArrayEncoder {
+	this.buf = binary.AppendInt16Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Int32(value int32) *ArrayEncoder {
+	this.buf = binary.AppendInt32Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Int64(value int64) *ArrayEncoder {
+	this.buf = binary.AppendInt64Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Uint(value uint) *ArrayEncoder {
+	return this.Uint64(uint64(value))
+}
+
+func (this *ArrayEncoder) Uint8(value uint8) *ArrayEncoder {
+	this.buf = binary.AppendUint8Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Uint16(value uint16) *ArrayEncoder {
+	this.buf = binary.AppendUint16Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Uint32(value uint32) *ArrayEncoder {
+	this.buf = binary.AppendUint32Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Uint64(value uint64) *ArrayEncoder {
+	this.buf = binary.AppendUint64Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Uintptr(value uintptr) *ArrayEncoder {
+	this.buf = binary.AppendUintptrContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Float32(value float32) *ArrayEncoder {
+	this.buf = binary.AppendFloat32Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Float64(value float64) *ArrayEncoder {
+	this.buf = binary.AppendFloat64Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Complex64(value complex64) *ArrayEncoder {
+	this.buf = binary.AppendComplex64Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Complex128(value complex128) *ArrayEncoder {
+	this.buf = binary.AppendComplex128Context(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Str(value string) *ArrayEncoder {
+	this.buf = binary.AppendStringContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Bytes(value []byte) *ArrayEncoder {
+	this.buf = binary.AppendBytesContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Err(err error) *ArrayEncoder {
+	this.buf = binary.AppendErrorContext(this.buf, "", err)
+	return this
+}
+
+func (this *ArrayEncoder) Time(value time.Time) *ArrayEncoder {
+	this.buf = binary.AppendTimeContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Duration(value time.Duration) *ArrayEncoder {
+	this.buf = binary.AppendDurationContext(this.buf, "", value)
+	return this
+}
+
+func (this *ArrayEncoder) Object(value LogObjectMarshaler) *ArrayEncoder {
+	if log := (*Log)(this); log.enterMarshaler("", value) {
+		log.appendObject("", value)
+		log.leaveMarshaler()
+	}
+	return this
+}
+
+func (this *ArrayEncoder) Array(value LogArrayMarshaler) *ArrayEncoder {
+	if log := (*Log)(this); log.enterMarshaler("", value) {
+		log.appendArray("", value)
+		log.leaveMarshaler()
+	}
+	return this
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+)
+
+type testUser struct {
+	id   int
+	name string
+	tags testTags
+}
+
+func (this testUser) MarshalLogObject(encoder *ObjectEncoder) {
+	encoder.Int("id", this.id).Str("name", this.name).Array("tags", this.tags)
+}
+
+type testTags []string
+
+func (this testTags) MarshalLogArray(encoder *ArrayEncoder) {
+	for _, tag := range this {
+		encoder.Str(tag)
+	}
+}
+
+func TestMarshalers(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	logger.Info().
+		Object("user", testUser{id: 7, name: "bob", tags: testTags{"a", "b"}}).
+		Array("none", nil).
+		Int("after", 1).
+		Commit("nested")
+
+	records := readRecords(t, dir)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	record := records[0]
+
+	user := record.Context("user")
+	if user == nil || user.Kind != binary.Object {
+		t.Fatalf("user = %+v", user)
+	}
+	fields := user.Elems()
+	if len(fields) != 3 || fields[0].Key != "id" || fields[0].Int() != 7 ||
+		fields[1].Key != "name" || fields[1].Str() != "bob" || fields[2].Kind != binary.Array {
+		t.Fatalf("user fields = %+v", fields)
+	}
+	tags := fields[2].Elems()
+	if len(tags) != 2 || tags[0].Str() != "a" || tags[1].Str() != "b" {
+		t.Errorf("tags = %+v", tags)
+	}
+
+	if none := record.Context("none"); none == nil || none.Kind != binary.Array || len(none.Elems()) != 0 {
+		t.Errorf("none = %+v", none)
+	}
+	if after := record.Context("after"); after == nil || after.Int() != 1 {
+		t.Errorf("context after the nested ones = %+v", after)
+	}
+}
+
+type testDeep int
+
+func (this testDeep) MarshalLogObject(encoder *ObjectEncoder) {
+	if this > 1 {
+		encoder.Object("next", this-1)
+	}
+}
+
+func TestNestingTooDeep(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	logger.Info().Object("deep", testDeep(40)).Int("after", 1).Commit("deep")
+
+	records := readRecords(t, dir)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	context := records[0].Context("deep")
+	for depth := 1; depth < binary.MaxNestingDepth; depth++ {
+		if context.Kind != binary.Object || len(context.Elems()) != 1 {
+			t.Fatalf("value at depth %d = %+v", depth, context)
+		}
+		context = &context.Elems()[0]
+	}
+	if context.Kind != binary.Object || len(context.Elems()) != 1 {
+		t.Fatalf("value at depth %d = %+v", binary.MaxNestingDepth, context)
+	}
+	if next := context.Elems()[0]; next.Kind != binary.String || next.Str() != tooDeepValue {
+		t.Errorf("value beyond the deepest = %+v, want %q", next, tooDeepValue)
+	}
+	if after := records[0].Context("after"); after == nil || after.Int() != 1 {
+		t.Errorf("context after the deep one = %+v", after)
+	}
+}
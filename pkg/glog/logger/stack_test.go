@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestStack(t *testing.T) {
+	logger, dir := newTestLogger(t)
+	logger.Info().Stack().Commit("explicit")
+	logger.Error().Commit("default")
+	logger.UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.AutoStack = true
+		config.StackLevel = iface.Warn
+		return config
+	})
+	logger.Warn().Commit("auto")
+	logger.Info().Commit("below")
+	logger.Error().Stack().Commit("once")
+
+	records := readRecords(t, dir)
+	if len(records) != 5 {
+		t.Fatalf("got %d records, want 5", len(records))
+	}
+	for _, record := range records {
+		stacked := record.Msg != "default" && record.Msg != "below"
+		if !stacked {
+			if len(record.Stack) != 0 {
+				t.Errorf("%s: unexpected stack %v", record.Msg, record.Stack)
+			}
+			continue
+		}
+		if len(record.Stack) == 0 {
+			t.Errorf("%s: no stack", record.Msg)
+			continue
+		}
+		top := record.Stack[0]
+		if !strings.HasSuffix(top.Func, ".TestStack") || !strings.HasSuffix(top.File, "stack_test.go") {
+			t.Errorf("%s: top frame = %+v, want TestStack", record.Msg, top)
+		}
+	}
+}
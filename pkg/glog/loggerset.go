@@ -1,7 +1,6 @@
 package glog
 
 import (
-	"runtime"
 	"strings"
 	"sync"
 
@@ -22,30 +21,47 @@ func internalLogger(name string) *ilog.Logger {
 	lock.Lock()
 	defer lock.Unlock()
 
+	return getLogger(name)
+}
+
+func getLogger(name string) *ilog.Logger {
 	logger := loggers[name]
 	if logger == nil {
-		logger = ilog.New()
+		var parent *ilog.Logger
+		if parentName, ok := parentName(name); ok {
+			parent = getLogger(parentName)
+			logger = ilog.NewChild(name, parent)
+		} else {
+			logger = ilog.New(name)
+		}
+		adoptDescendants(logger, parent)
 		loggers[name] = logger
 	}
 
 	return logger
 }
 
-func callerPkg(frameSkip int) string {
-	pc, _, _, ok := runtime.Caller(frameSkip + 1)
-	if ok {
-		return pkgName(pc)
-	} else {
-		return "???"
+// adoptDescendants relinks the loggers below the name of logger to it.
+func adoptDescendants(logger, parent *ilog.Logger) {
+	if logger.Name() == "" {
+		return
+	}
+	prefix := logger.Name() + "."
+	for name, descendant := range loggers {
+		if strings.HasPrefix(name, prefix) && descendant.Parent() == parent {
+			descendant.SetParent(logger)
+		}
 	}
 }
 
-func pkgName(pc uintptr) string {
-	name := runtime.FuncForPC(pc).Name()
-	lastSlash := strings.LastIndexByte(name, '/')
-	nextDot := strings.IndexByte(name[lastSlash+1:], '.')
-	if nextDot < 0 {
-		return "???"
+func parentName(name string) (string, bool) {
+	lastDot := strings.LastIndexByte(name, '.')
+	if lastDot <= 0 {
+		return "", false
 	}
-	return name[:lastSlash+1+nextDot]
+	return name[:lastDot], true
+}
+
+func callerPkg(frameSkip int) string {
+	return logger.CallerPkg(frameSkip + 1)
 }
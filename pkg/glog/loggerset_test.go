@@ -0,0 +1,54 @@
+package glog
+
+import (
+	"testing"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestParentName(t *testing.T) {
+	tests := []struct {
+		name, parent string
+		ok           bool
+	}{
+		{"svc.db.pool", "svc.db", true},
+		{"svc", "", false},
+		{".svc", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		if parent, ok := parentName(test.name); parent != test.parent || ok != test.ok {
+			t.Errorf("parentName(%q) = %q, %v", test.name, parent, ok)
+		}
+	}
+}
+
+func TestHierarchy(t *testing.T) {
+	dir := useFiles(t, "tree")
+
+	pool := Logger("tree.db.pool")
+	if internalLogger("tree.db").Parent() != internalLogger("tree") {
+		t.Fatal("parent not created along with the child")
+	}
+	if config := pool.Config(); config.FileWriter.Dir != dir {
+		t.Errorf("inherited dir = %q, want %q", config.FileWriter.Dir, dir)
+	}
+
+	err := Logger("tree").UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Level = iface.Warn
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Info() != nil {
+		t.Error("Info enabled below a parent at level Warn")
+	}
+	pool.Warn().Commit("pool")
+	Logger("tree").Warn().Commit("tree")
+
+	_, msgs := readMsgs(t, dir)
+	if len(msgs) != 2 || msgs[0] != "pool" || msgs[1] != "tree" {
+		t.Errorf("got %q, want [pool tree] written by the parent", msgs)
+	}
+}
@@ -0,0 +1,195 @@
+package glog
+
+import (
+	"fmt"
+	"sort"
+
+	ilog "github.com/gratonos/glog/internal/logger"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+type LoggerInfo struct {
+	Name      string
+	Config    iface.Logger
+	Inherited bool
+}
+
+type State struct {
+	loggers     []LoggerInfo
+	defaultName string
+}
+
+func Loggers() []LoggerInfo {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return loggerInfos()
+}
+
+// Remove closes the named logger and drops it from the set. Its logs go to
+// its parent, or to the default logger if it has none.
+func Remove(name string) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return remove(name)
+}
+
+// UpdateAll sets the configs returned by updater, or none if any is invalid.
+func UpdateAll(updater func(name string, config iface.Logger) iface.Logger) error {
+	if updater == nil {
+		panic("glog: update all: updater is nil")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	var targets []*ilog.Logger
+	var configs []iface.Logger
+	for _, info := range loggerInfos() {
+		if info.Inherited {
+			continue
+		}
+		logger := loggers[info.Name]
+		config := updater(info.Name, info.Config)
+		if err := logger.CheckConfig(config); err != nil {
+			return fmt.Errorf("glog: update all: '%s': %v", info.Name, err)
+		}
+		targets = append(targets, logger)
+		configs = append(configs, config)
+	}
+	for i, config := range configs {
+		if err := ilog.MakeDir(config); err != nil {
+			return fmt.Errorf("glog: update all: '%s': %v", targets[i].Name(), err)
+		}
+	}
+
+	if err := ilog.ApplyConfigs(targets, configs); err != nil {
+		return fmt.Errorf("glog: update all: %v", err)
+	}
+	return nil
+}
+
+// Snapshot returns the configs of the loggers and the default logger name.
+func Snapshot() *State {
+	lock.Lock()
+	defer lock.Unlock()
+
+	return &State{
+		loggers:     loggerInfos(),
+		defaultName: defaultName,
+	}
+}
+
+// Restore brings the set back to snapshot, except for hooks.
+func Restore(snapshot *State) error {
+	if snapshot == nil {
+		panic("glog: restore: snapshot is nil")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	setDefault(snapshot.defaultName)
+
+	kept := make(map[string]bool, len(snapshot.loggers))
+	for _, info := range snapshot.loggers {
+		kept[info.Name] = true
+	}
+	names := sortedNames()
+	for i := len(names) - 1; i >= 0; i-- {
+		if !kept[names[i]] {
+			if err := remove(names[i]); err != nil {
+				return fmt.Errorf("glog: restore: %v", err)
+			}
+		}
+	}
+
+	for _, info := range snapshot.loggers {
+		if err := restore(getLogger(info.Name), &info); err != nil {
+			return fmt.Errorf("glog: restore: '%s': %v", info.Name, err)
+		}
+	}
+	return nil
+}
+
+func loggerInfos() []LoggerInfo {
+	names := sortedNames()
+	infos := make([]LoggerInfo, 0, len(names))
+	for _, name := range names {
+		logger := loggers[name]
+		infos = append(infos, LoggerInfo{
+			Name:      name,
+			Config:    logger.Config(),
+			Inherited: logger.Inherited(),
+		})
+	}
+	return infos
+}
+
+func sortedNames() []string {
+	names := make([]string, 0, len(loggers))
+	for name := range loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func remove(name string) error {
+	logger := loggers[name]
+	if logger == nil {
+		return fmt.Errorf("glog: remove: '%s' does not exist", name)
+	}
+	if name == defaultName {
+		return fmt.Errorf("glog: remove: '%s' is the default logger", name)
+	}
+
+	parent := logger.Parent()
+	config := logger.Config()
+	var children, orphans []*ilog.Logger
+	var configs []iface.Logger
+	for _, child := range loggers {
+		if child.Parent() != logger {
+			continue
+		}
+		children = append(children, child)
+		if child.Inherited() && parent == nil {
+			orphans = append(orphans, child)
+			configs = append(configs, config)
+		}
+	}
+	if len(orphans) != 0 {
+		if err := logger.CheckConfig(config); err != nil {
+			return fmt.Errorf("glog: remove: '%s': %v", name, err)
+		}
+		if err := ilog.MakeDir(config); err != nil {
+			return fmt.Errorf("glog: remove: '%s': %v", name, err)
+		}
+	}
+
+	// Close the files before the orphans take over the config.
+	heir := parent
+	if heir == nil {
+		heir = getDefault()
+	}
+	err := logger.Retire(heir)
+	delete(loggers, name)
+	for _, child := range children {
+		child.SetParent(parent)
+	}
+	if applyErr := ilog.ApplyConfigs(orphans, configs); err == nil {
+		err = applyErr
+	}
+	if err != nil {
+		return fmt.Errorf("glog: remove: '%s': %v", name, err)
+	}
+	return nil
+}
+
+func restore(logger *ilog.Logger, info *LoggerInfo) error {
+	if info.Inherited && logger.Parent() != nil {
+		return logger.Inherit()
+	}
+	return logger.SetConfig(info.Config)
+}
@@ -0,0 +1,213 @@
+package glog
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+func TestUpdateAll(t *testing.T) {
+	useFiles(t, "all.a")
+	useFiles(t, "all.b")
+	setLevels := func(a, b iface.Level) error {
+		return UpdateAll(func(name string, config iface.Logger) iface.Logger {
+			switch name {
+			case "all.a":
+				config.Level = a
+			case "all.b":
+				config.Level = b
+			}
+			return config
+		})
+	}
+
+	if err := setLevels(iface.Warn, iface.Level(42)); err == nil {
+		t.Fatal("illegal level accepted")
+	}
+	if level := Logger("all.a").Config().Level; level != iface.Trace {
+		t.Errorf("level of all.a = %v after a failed update", level)
+	}
+	newDir := filepath.Join(t.TempDir(), "new")
+	err := UpdateAll(func(name string, config iface.Logger) iface.Logger {
+		switch name {
+		case "all.a":
+			config.FileWriter.Dir = newDir
+		case "all.b":
+			config.Level = iface.Level(42)
+		}
+		return config
+	})
+	if err == nil {
+		t.Fatal("illegal level accepted")
+	}
+	if _, err := os.Stat(newDir); !os.IsNotExist(err) {
+		t.Errorf("failed update created %s", newDir)
+	}
+
+	// Logs committed meanwhile must not race with the update.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for _, name := range []string{"all.a", "all.b"} {
+		logger := Logger(name)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Error().Commit("concurrent")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		if err := setLevels(iface.Warn, iface.Error); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if Logger("all.a").Config().Level != iface.Warn || Logger("all.b").Config().Level != iface.Error {
+		t.Error("levels not updated")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := useFiles(t, "rm")
+	defaultDir := useFiles(t, "")
+	t.Cleanup(func() { Remove("rm.child") })
+
+	removed := Logger("rm")
+	child := Logger("rm.child")
+	removed.Info().Commit("before")
+	if err := Remove("rm"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := removed.UpdateConfig(func(config iface.Logger) iface.Logger { return config }); err == nil {
+		t.Error("config of a removed logger changed")
+	}
+	if err := removed.AddHook("hook", func(*binary.Record) bool { return true }); err == nil {
+		t.Error("hook added to a removed logger")
+	}
+	if internalLogger("rm.child").Parent() != nil || internalLogger("rm.child").Inherited() {
+		t.Error("orphan still linked")
+	}
+	if config := child.Config(); config.FileWriter.Dir != dir {
+		t.Errorf("orphan dir = %q, want %q", config.FileWriter.Dir, dir)
+	}
+	child.Info().Commit("after")
+	removed.Info().Commit("forwarded")
+
+	_, msgs := readMsgs(t, dir)
+	if len(msgs) != 2 || msgs[0] != "before" || msgs[1] != "after" {
+		t.Errorf("got %q, want [before after]", msgs)
+	}
+	if _, msgs := readMsgs(t, defaultDir); len(msgs) != 1 || msgs[0] != "forwarded" {
+		t.Errorf("default logger got %q, want the log of the removed one", msgs)
+	}
+
+	if err := Remove("rm"); err == nil {
+		t.Error("removed a logger twice")
+	}
+	if err := Remove(""); err == nil {
+		t.Error("removed the default logger")
+	}
+}
+
+func TestRemoveRelink(t *testing.T) {
+	t.Cleanup(func() {
+		for _, name := range []string{"link.db.pool", "link.db", "link"} {
+			Remove(name)
+		}
+	})
+
+	Logger("link.db.pool")
+	if err := Remove("link.db"); err != nil {
+		t.Fatal(err)
+	}
+	if internalLogger("link.db.pool").Parent() != internalLogger("link") {
+		t.Error("child not moved to the grandparent")
+	}
+
+	Logger("link.db")
+	if internalLogger("link.db.pool").Parent() != internalLogger("link.db") {
+		t.Error("child not relinked to the recreated parent")
+	}
+	if internalLogger("link.dbx").Parent() != internalLogger("link") {
+		t.Error("sibling with a common prefix linked to the wrong parent")
+	}
+	Remove("link.dbx")
+}
+
+func TestRemoveConcurrently(t *testing.T) {
+	dir := useFiles(t, "race")
+	t.Cleanup(func() {
+		Remove("race.a.b")
+		Remove("race.a")
+	})
+	var wg sync.WaitGroup
+	internalLogger("race").AddHook("count", func(*binary.Record) bool { return true })
+	defer internalLogger("race").RemoveHook("count")
+
+	const n = 200
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				Logger("race.a.b").Info().Commit("log")
+				Logger("race.a").Info().Commit("log")
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		Logger("race.a")
+		if err := Remove("race.a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	if _, msgs := readMsgs(t, dir); len(msgs) != 4*2*n {
+		t.Errorf("got %d logs, want %d", len(msgs), 4*2*n)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	useFiles(t, "snap")
+	state := Snapshot()
+	t.Cleanup(func() { Remove("snap.new") })
+
+	err := Logger("snap").UpdateConfig(func(config iface.Logger) iface.Logger {
+		config.Level = iface.Fatal
+		return config
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Logger("snap.new")
+	SetDefault("snap")
+
+	if err := Restore(state); err != nil {
+		t.Fatal(err)
+	}
+	if Logger("snap").Config().Level != iface.Trace {
+		t.Error("config not restored")
+	}
+	if getDefault() != internalLogger("") {
+		t.Error("default logger not restored")
+	}
+	for _, info := range Loggers() {
+		if info.Name == "snap.new" {
+			t.Error("logger created after the snapshot kept")
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package reader
+
+import (
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/internal/writers/file"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// CorruptionHandler is called with each damaged region Read skips. If it
+// returns true, Read goes on rather than returning err.
+type CorruptionHandler func(path string, err *CorruptionError) bool
+
+// Reader reads records from binary log files. A record read is only valid
+// until the next read.
+type Reader struct {
+	reader  *binary.Reader
+	file    *os.File
+	path    string
+	paths   []string
+	since   time.Time
+	handler CorruptionHandler
+}
+
+// New creates a Reader that reads records from reader.
+func New(reader io.Reader) *Reader {
+	return &Reader{
+		reader: binary.NewReader(reader),
+	}
+}
+
+// Open creates a Reader that reads the file at path, or the binary log files
+// under it in the order of their paths.
+func Open(path string) (*Reader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		if paths, err = logFiles(path); err != nil {
+			return nil, err
+		}
+	}
+	return &Reader{
+		paths: paths,
+	}, nil
+}
+
+// SetCorruptionHandler sets the handler called with the damaged regions Read
+// skips.
+func (this *Reader) SetCorruptionHandler(handler CorruptionHandler) {
+	this.handler = handler
+}
+
+// SetSince makes Read skip the records logged before tm.
+func (this *Reader) SetSince(tm time.Time) {
+	this.since = tm
+}
+
+// Path returns the path of the file being read.
+func (this *Reader) Path() string {
+	return this.path
+}
+
+// Header returns the header of the file being read, or nil if it has none.
+func (this *Reader) Header() *FileHeader {
+	if this.reader == nil {
+		return nil
+	}
+	return this.reader.Header()
+}
+
+// Read reads the next record, going on through the files, and returns EOF
+// after the last. Reading again after EOF picks up appended records.
+func (this *Reader) Read(record *Record) error {
+	if record == nil {
+		panic("glog: read log: record is nil")
+	}
+
+	for {
+		if this.reader == nil {
+			if len(this.paths) == 0 {
+				return EOF
+			}
+			if err := this.openNext(); err != nil {
+				return err
+			}
+		}
+
+		err := this.reader.Read(record)
+		if err == nil {
+			if record.Time.Before(this.since) {
+				continue
+			}
+			return nil
+		}
+		if corruption, ok := err.(*CorruptionError); ok {
+			if this.handler != nil && this.handler(this.path, corruption) {
+				continue
+			}
+			return err
+		}
+		if this.file == nil || this.following(err) {
+			return err
+		}
+		this.closeFile()
+		if err != EOF {
+			return err
+		}
+	}
+}
+
+// Records returns an iterator over the records read, yielding the same
+// *Record each time. Damaged regions are yielded as *CorruptionError.
+func (this *Reader) Records() iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		var record Record
+		for {
+			err := this.Read(&record)
+			if err == EOF {
+				return
+			}
+			if err != nil {
+				if !yield(nil, err) {
+					return
+				}
+				if _, ok := err.(*CorruptionError); !ok {
+					return
+				}
+				continue
+			}
+			if !yield(&record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes the file being read, and the rest are left unread.
+func (this *Reader) Close() error {
+	this.paths = nil
+	return this.closeFile()
+}
+
+// following tells whether err leaves the reader at the end of the last file.
+func (this *Reader) following(err error) bool {
+	if len(this.paths) != 0 {
+		return false
+	}
+	_, truncated := err.(*TruncatedError)
+	return err == EOF || truncated
+}
+
+func (this *Reader) openNext() error {
+	path := this.paths[0]
+	this.paths = this.paths[1:]
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	this.file, this.path = f, path
+	this.reader = binary.NewReader(f)
+	this.reader.ReadHeader()
+	if !this.since.IsZero() {
+		this.seekSince()
+	}
+	return nil
+}
+
+// seekSince moves to the records logged since this.since, if there is an index.
+func (this *Reader) seekSince() {
+	ext := file.Extensions[iface.Binary]
+	if !strings.HasSuffix(this.path, ext) {
+		return
+	}
+	data, err := os.ReadFile(strings.TrimSuffix(this.path, ext) + file.IndexExtension)
+	if err != nil {
+		return
+	}
+	entries, err := binary.ParseIndex(data)
+	if err != nil {
+		return
+	}
+	if offset := binary.SearchIndex(entries, this.since); offset > this.reader.Offset() {
+		this.reader.SeekTo(offset)
+	}
+}
+
+func (this *Reader) closeFile() error {
+	if this.file == nil {
+		return nil
+	}
+	err := this.file.Close()
+	this.file, this.reader = nil, nil
+	return err
+}
+
+func logFiles(dir string) ([]string, error) {
+	var paths []string
+	ext := file.Extensions[iface.Binary]
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+		} else if !strings.HasPrefix(name, ".") && strings.HasSuffix(name, ext) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
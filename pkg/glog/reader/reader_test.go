@@ -0,0 +1,319 @@
+package reader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gratonos/glog/internal/encoding/binary"
+	"github.com/gratonos/glog/internal/writers/file"
+	"github.com/gratonos/glog/pkg/glog/iface"
+)
+
+// testTimes returns the times of n records logged a millisecond apart, the
+// first at the time given.
+func testTimes(first time.Time, n int) []time.Time {
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = first.Add(time.Duration(i) * time.Millisecond)
+	}
+	return times
+}
+
+// writeLogs logs a record at each of times through a file writer into dir,
+// along with an index, and returns the paths of the files written.
+func writeLogs(t *testing.T, dir string, times []time.Time) []string {
+	t.Helper()
+
+	writer := file.NewWriter("svc")
+	config := iface.FileWriter{Enable: true, Dir: dir, Format: iface.Binary, Index: true}
+	if err := writer.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	for i, tm := range times {
+		record := binary.Record{Time: tm, Seq: uint64(i + 1), Level: iface.Info, Pkg: "test", Msg: fmt.Sprintf("msg %d", i)}
+		record.AddContext("int", int64(i))
+		record.AddContext("str", "value")
+		record.AddContext("bytes", []byte{1, 2, 3})
+		record.AddContext("time", tm)
+		writer.Write(binary.AppendRecord(nil, &record), tm)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*"+file.Extensions[iface.Binary]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+// damage changes the msg of the i-th record in the file at path.
+func damage(t *testing.T, path string, i int) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[bytes.Index(data, []byte(fmt.Sprintf("msg %d", i)))] = 'M'
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readSeqs reads all records and returns their seqs, along with the number of
+// corruptions met.
+func readSeqs(t *testing.T, reader *Reader) (seqs []uint64, corruptions int) {
+	t.Helper()
+
+	for record, err := range reader.Records() {
+		if _, ok := err.(*CorruptionError); ok {
+			corruptions++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs = append(seqs, record.Seq)
+	}
+	return seqs, corruptions
+}
+
+func seqRange(from, to uint64) []uint64 {
+	var seqs []uint64
+	for seq := from; seq <= to; seq++ {
+		seqs = append(seqs, seq)
+	}
+	return seqs
+}
+
+func TestOpenDir(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2024, 5, 6, 23, 59, 59, 998e6, time.Local)
+	paths := writeLogs(t, dir, testTimes(first, 5))
+	if len(paths) != 2 {
+		t.Fatalf("got %d files, want one for each day", len(paths))
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".hidden/a.log.bin", "2024_0506/.a.log.bin", "2024_0506/a.log.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	var seq uint64
+	for record, err := range reader.Records() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		seq++
+		if record.Seq != seq {
+			t.Fatalf("seq = %d, want %d", record.Seq, seq)
+		}
+		if want := paths[seq/3]; reader.Path() != want {
+			t.Errorf("record %d read from %s, want %s", seq, reader.Path(), want)
+		}
+		if header := reader.Header(); header == nil || header.Logger != "svc" {
+			t.Errorf("header of %s = %+v", reader.Path(), header)
+		}
+	}
+	if seq != 5 {
+		t.Errorf("read %d records, want 5", seq)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+	paths := writeLogs(t, t.TempDir(), []time.Time{tm})
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := New(bytes.NewReader(data))
+	var record Record
+	if err := reader.Read(&record); err != nil {
+		t.Fatal(err)
+	}
+	if reader.Path() != "" {
+		t.Errorf("path = %q, want empty", reader.Path())
+	}
+	if v := record.Context("int"); v.Kind != Int64 || v.Int() != 0 {
+		t.Errorf("int = %+v", v)
+	}
+	if v := record.Context("str"); v.Kind != String || v.Str() != "value" {
+		t.Errorf("str = %+v", v)
+	}
+	if v := record.Context("bytes"); v.Kind != Bytes || !bytes.Equal(v.Bytes(), []byte{1, 2, 3}) {
+		t.Errorf("bytes = %+v", v)
+	}
+	if v := record.Context("time"); v.Kind != Time || !v.Time().Equal(tm) {
+		t.Errorf("time = %+v", v)
+	}
+	if record.Context("none") != nil {
+		t.Error("context that was not logged found")
+	}
+	if err := reader.Read(&record); err != EOF {
+		t.Errorf("read at the end = %v, want EOF", err)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	times := testTimes(time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local), 3)
+	data, err := os.ReadFile(writeLogs(t, t.TempDir(), times)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	split := bytes.Index(data, []byte("msg 2"))
+	path := filepath.Join(t.TempDir(), "svc"+file.Extensions[iface.Binary])
+	if err := os.WriteFile(path, data[:split], 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	var record Record
+	for seq := uint64(1); seq <= 2; seq++ {
+		if err := reader.Read(&record); err != nil || record.Seq != seq {
+			t.Fatalf("read = %v, seq %d, want seq %d", err, record.Seq, seq)
+		}
+	}
+	var truncated *TruncatedError
+	if err := reader.Read(&record); !errors.As(err, &truncated) {
+		t.Fatalf("read of a partial record = %v, want a TruncatedError", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Write(data[split:])
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reader.Read(&record); err != nil || record.Seq != 3 {
+		t.Fatalf("read after the record was completed = %v, seq %d", err, record.Seq)
+	}
+	if err := reader.Read(&record); err != EOF {
+		t.Errorf("read at the end = %v, want EOF", err)
+	}
+}
+
+// checkResumed checks that seqs are those of n records logged with the i-th
+// damaged, from which reading resumed at a later one.
+func checkResumed(t *testing.T, seqs []uint64, n, i int) {
+	t.Helper()
+
+	if len(seqs) <= i || fmt.Sprint(seqs[:i]) != fmt.Sprint(seqRange(1, uint64(i))) {
+		t.Fatalf("read %v, want the records before the damaged one", seqs)
+	}
+	for j := i; j < len(seqs); j++ {
+		if seqs[j] <= uint64(i+1) || j > i && seqs[j] != seqs[j-1]+1 {
+			t.Fatalf("read %v, want to resume after the damaged record", seqs)
+		}
+	}
+	if seqs[len(seqs)-1] != uint64(n) {
+		t.Fatalf("read %v, want up to the last record", seqs)
+	}
+}
+
+func TestCorruptionHandler(t *testing.T) {
+	times := testTimes(time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local), 200)
+	path := writeLogs(t, t.TempDir(), times)[0]
+	damage(t, path, 10)
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	var corruptions []*CorruptionError
+	reader.SetCorruptionHandler(func(p string, err *CorruptionError) bool {
+		if p != path {
+			t.Errorf("corruption reported in %s, want %s", p, path)
+		}
+		corruptions = append(corruptions, err)
+		return true
+	})
+	seqs, _ := readSeqs(t, reader)
+	if len(corruptions) != 1 {
+		t.Fatalf("%d corruptions reported, want 1", len(corruptions))
+	}
+	checkResumed(t, seqs, 200, 10)
+
+	// Without a handler, the iteration yields the corruption and goes on.
+	if reader, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	seqs, corrupted := readSeqs(t, reader)
+	if corrupted != 1 {
+		t.Errorf("iteration met %d corruptions, want 1", corrupted)
+	}
+	checkResumed(t, seqs, 200, 10)
+}
+
+func TestSetSince(t *testing.T) {
+	times := testTimes(time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local), 3000)
+	dir := t.TempDir()
+	path := writeLogs(t, dir, times)[0]
+	// Seeking by the index skips a damaged record before the time sought.
+	damage(t, path, 10)
+
+	reader, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	reader.SetSince(times[2500])
+	seqs, corrupted := readSeqs(t, reader)
+	if corrupted != 0 || fmt.Sprint(seqs) != fmt.Sprint(seqRange(2501, 3000)) {
+		t.Errorf("read %v with %d corruptions", seqs, corrupted)
+	}
+
+	// Without an index, the records before are read and skipped.
+	index, _ := filepath.Glob(filepath.Join(dir, "*", "*"+file.IndexExtension))
+	if len(index) != 1 {
+		t.Fatalf("got %d indexes, want 1", len(index))
+	}
+	if err := os.Remove(index[0]); err != nil {
+		t.Fatal(err)
+	}
+	if reader, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	reader.SetSince(times[2500])
+	seqs, corrupted = readSeqs(t, reader)
+	if corrupted != 1 || fmt.Sprint(seqs) != fmt.Sprint(seqRange(2501, 3000)) {
+		t.Errorf("read %v with %d corruptions without an index", seqs, corrupted)
+	}
+}
+
+func TestOpenMissing(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "none")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("open of a missing path = %v", err)
+	}
+}
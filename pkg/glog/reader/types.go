@@ -0,0 +1,53 @@
+package reader
+
+import (
+	"github.com/gratonos/glog/internal/encoding/binary"
+)
+
+type (
+	Record     = binary.Record
+	Context    = binary.Context
+	ValueKind  = binary.ValueKind
+	ErrorValue = binary.ErrorValue
+	Frame      = binary.Frame
+	FileHeader = binary.FileHeader
+)
+
+const (
+	Bool       = binary.Bool
+	Byte       = binary.Byte
+	Rune       = binary.Rune
+	Int8       = binary.Int8
+	Int16      = binary.Int16
+	Int32      = binary.Int32
+	Int64      = binary.Int64
+	Uint8      = binary.Uint8
+	Uint16     = binary.Uint16
+	Uint32     = binary.Uint32
+	Uint64     = binary.Uint64
+	Uintptr    = binary.Uintptr
+	Float32    = binary.Float32
+	Float64    = binary.Float64
+	Complex64  = binary.Complex64
+	Complex128 = binary.Complex128
+	String     = binary.String
+	Time       = binary.Time
+	Duration   = binary.Duration
+	Error      = binary.Error
+	Array      = binary.Array
+	Object     = binary.Object
+	Bytes      = binary.Bytes
+)
+
+type (
+	IOError         = binary.IOError
+	MagicError      = binary.MagicError
+	VersionError    = binary.VersionError
+	FormatError     = binary.FormatError
+	ChecksumError   = binary.ChecksumError
+	CorruptionError = binary.CorruptionError
+	TruncatedError  = binary.TruncatedError
+)
+
+// EOF is returned by Read when there are no more records.
+var EOF = binary.EOF
@@ -9,10 +9,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gratonos/glog/internal/encoding/binary"
 	"github.com/gratonos/glog/internal/encoding/text"
 	"github.com/gratonos/glog/internal/writers/file"
 	"github.com/gratonos/glog/pkg/glog/iface"
+	"github.com/gratonos/glog/pkg/glog/reader"
 )
 
 func processPath(path string) {
@@ -43,6 +43,12 @@ func processPath(path string) {
 
 func processFile(path string, modTime time.Time) {
 	outPath := toOutPath(path)
+	if !since.IsZero() {
+		convertFile(path, outPath)
+		// Make the partial output look outdated to the next full conversion.
+		os.Chtimes(outPath, modTime.Add(-time.Second), modTime.Add(-time.Second))
+		return
+	}
 	outInfo, err := os.Stat(outPath)
 	if err != nil || modTime.Sub(outInfo.ModTime()) > 0 {
 		convertFile(path, outPath)
@@ -65,12 +71,13 @@ func toOutPath(path string) string {
 }
 
 func convertFile(inPath, outPath string) {
-	inFile, err := os.Open(inPath)
+	in, err := reader.Open(inPath)
 	if err != nil {
 		errorf("processing %s: %v", inPath, err)
 		return
 	}
-	defer inFile.Close()
+	defer in.Close()
+	in.SetSince(since)
 
 	outFile, err := os.Create(outPath)
 	if err != nil {
@@ -79,40 +86,44 @@ func convertFile(inPath, outPath string) {
 	}
 	defer outFile.Close()
 
-	in, out := bufio.NewReader(inFile), bufio.NewWriter(outFile)
+	out := bufio.NewWriter(outFile)
 	defer out.Flush()
 
 	convert(in, out, context.WithValue(context.Background(), "path", inPath))
 }
 
-func convert(in *bufio.Reader, out *bufio.Writer, ctx context.Context) {
+func convert(in *reader.Reader, out *bufio.Writer, ctx context.Context) {
 	path := ctx.Value("path")
 
+	var record reader.Record
+	var header reader.FileHeader
 	var readErr, writeErr error
 	for {
-		var record binary.Record
-		if readErr == nil {
-			readErr = binary.ReadRecord(&record, in)
-		} else {
-			readErr = binary.TryReadRecord(&record, in)
+		readErr = in.Read(&record)
+		if h := in.Header(); h != nil && *h != header {
+			header = *h
+			if _, writeErr = out.Write(text.FormatHeader(h, textConfig)); writeErr != nil {
+				errorf("processing %s: %v", path, writeErr)
+				return
+			}
 		}
-		if readErr == binary.EOF {
+		if readErr == reader.EOF {
 			infof("processing %s ... done", path)
 			return
 		}
 		if readErr == nil {
-			_, writeErr = out.Write(text.FormatRecord(&record, flagColoring))
+			_, writeErr = out.Write(text.FormatRecord(&record, textConfig))
 		} else {
-			if ioErr, ok := readErr.(*binary.IOError); ok {
-				errorf("processing %s: %v", path, ioErr)
+			if corruption, ok := readErr.(*reader.CorruptionError); ok {
+				warnf("processing %s: %v", path, readErr)
+				_, writeErr = out.WriteString(corruptionLog(corruption))
+			} else if _, ok := readErr.(*reader.TruncatedError); ok {
+				// The rest is converted once the file is modified again.
+				warnf("processing %s: %v", path, readErr)
 				return
 			} else {
-				warnf("processing %s: corrupted log, locating next log ...", path)
-				log := "!!!!!!!! one or more corrupted logs !!!!!!!!"
-				if flagColoring {
-					log = fmt.Sprintf("%s%s%s", text.Magenta, log, text.Reset)
-				}
-				_, writeErr = out.WriteString(log + "\n")
+				errorf("processing %s: %v", path, readErr)
+				return
 			}
 		}
 		if writeErr != nil {
@@ -121,3 +132,11 @@ func convert(in *bufio.Reader, out *bufio.Writer, ctx context.Context) {
 		}
 	}
 }
+
+func corruptionLog(err *reader.CorruptionError) string {
+	log := fmt.Sprintf("!!!!!!!! corrupted logs: %d bytes at offset %d !!!!!!!!", err.Size, err.Offset)
+	if textConfig.Coloring {
+		log = fmt.Sprintf("%s%s%s", text.Magenta, log, text.Reset)
+	}
+	return log + "\n"
+}
@@ -3,16 +3,90 @@ package main
 import (
 	"flag"
 	"fmt"
+	"time"
+
+	"github.com/gratonos/glog/pkg/glog/iface"
 )
 
 var (
-	flagColoring bool
+	flagColoring   bool
+	flagBytes      string
+	flagBytesLimit int
+	flagSince      string
+	flagZone       string
+
+	textConfig iface.TextConfig
+	since      time.Time
 )
 
+var sinceLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var bytesFormats = map[string]iface.BytesFormat{
+	"hex":    iface.BytesHex,
+	"base64": iface.BytesBase64,
+	"quoted": iface.BytesQuoted,
+}
+
 func initFlags() {
 	flag.Usage = usage
 
 	flag.BoolVar(&flagColoring, "color", true, "enable coloring")
+	flag.StringVar(&flagBytes, "bytes", "hex", "render bytes as hex (a hex dump), base64 or quoted (quoted-printable)")
+	flag.IntVar(&flagBytesLimit, "bytes-limit", 0, "truncate bytes longer than this, 0 for no limit")
+	flag.StringVar(&flagSince, "since", "",
+		"convert only logs at or after this time in the zone of -zone, e.g. '2006-01-02 15:04:05'")
+	flag.StringVar(&flagZone, "zone", "",
+		"render times in this zone, e.g. 'Local', 'UTC' or 'Asia/Shanghai', empty for the zone logged in")
+}
+
+func checkFlags() error {
+	bytesFormat, ok := bytesFormats[flagBytes]
+	if !ok {
+		return fmt.Errorf("illegal bytes format '%s'", flagBytes)
+	}
+	if flagBytesLimit < 0 {
+		return fmt.Errorf("illegal bytes limit %d", flagBytesLimit)
+	}
+
+	var location *time.Location
+	if flagZone != "" {
+		var err error
+		if location, err = time.LoadLocation(flagZone); err != nil {
+			return fmt.Errorf("illegal zone '%s'", flagZone)
+		}
+	}
+	if flagSince != "" {
+		var err error
+		if since, err = parseTime(flagSince, location); err != nil {
+			return err
+		}
+	}
+
+	textConfig = iface.TextConfig{
+		Coloring:    flagColoring,
+		BytesFormat: bytesFormat,
+		BytesLimit:  flagBytesLimit,
+		Location:    location,
+	}
+	return nil
+}
+
+func parseTime(value string, location *time.Location) (time.Time, error) {
+	if location == nil {
+		location = time.Local
+	}
+	for _, layout := range sinceLayouts {
+		if tm, err := time.ParseInLocation(layout, value, location); err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("illegal time '%s'", value)
 }
 
 func usage() {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"os"
 )
 
 func init() {
@@ -12,6 +13,10 @@ func init() {
 
 func main() {
 	flag.Parse()
+	if err := checkFlags(); err != nil {
+		errorf("%v", err)
+		os.Exit(2)
+	}
 
 	args := flag.Args()
 	if len(args) == 0 {